@@ -1,26 +1,33 @@
-#!/bin/bash
-# Bash completion script for goto command
+# bash completion for goto
+# Generated by "goto completion bash"
 
 _goto_completion() {
-    local cur prev opts
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
     COMPREPLY=()
-    cur="${COMP_WORDS[COMP_CWORD]}"
-    prev="${COMP_WORDS[COMP_CWORD-1]}"
 
-    # Basic options
-    opts="-h --help help --complete"
+    case "$prev" in
+        --config-file|--history-file|--keys-file)
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return 0
+            ;;
+        --keys)
+            return 0
+            ;;
+    esac
 
-    if [[ ${cur} == -* ]]; then
-        COMPREPLY=($(compgen -W "${opts}" -- ${cur}))
-        return 0
-    fi
+    local IFS=$'\n'
+    local line
+    local candidates=()
+    for line in $(goto --complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+        candidates+=("${line%%$'\t'*}")
+    done
+    COMPREPLY=($(compgen -W "${candidates[*]}" -- "$cur"))
 
-    # Get completion candidates from goto command
-    if command -v goto >/dev/null 2>&1; then
-        local candidates=$(goto --complete 2>/dev/null)
-        COMPREPLY=($(compgen -W "${candidates}" -- ${cur}))
+    # Keep completing beneath a directory, as cd does
+    if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == */ ]]; then
+        compopt -o nospace 2>/dev/null
     fi
-
     return 0
 }
 
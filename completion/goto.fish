@@ -0,0 +1,23 @@
+# fish completion for goto
+# Generated by "goto completion fish"
+
+function __goto_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l current (commandline -ct)
+    goto --complete $tokens "$current" 2>/dev/null
+end
+
+complete -c goto -f
+complete -c goto -l config-file -r -F -d 'Use the specified configuration file'
+complete -c goto -l history-file -r -F -d 'Use the specified history file'
+complete -c goto -l profile -x -d 'Use the config and history files of the profile'
+complete -c goto -l lang -x -d 'Set the language of messages (e.g. ja, en, zh-Hant)'
+complete -c goto -l tag -x -d 'Only show destinations with matching tags, e.g. work,!archived'
+complete -c goto -l sort -x -d 'Order the destinations: history, frecency, alphabetical or config'
+complete -c goto -s c -d 'Show the interactive menu in cursor mode'
+complete -c goto -s l -d 'Show the interactive menu in label input mode'
+complete -c goto -l keys -x -d 'Play back keys (e.g. down,down,enter) to the menu on a virtual screen'
+complete -c goto -l keys-file -r -F -d 'Play back keys (e.g. down,down,enter) to the menu on a virtual screen'
+complete -c goto -l snapshots -d 'Print every frame of the menu during playback'
+complete -c goto -a '(__goto_complete)'
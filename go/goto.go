@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/user"
@@ -21,23 +22,98 @@ const (
 
 // Destination represents a goto destination
 type Destination struct {
-	Path     string `toml:"path"`
-	Shortcut string `toml:"shortcut"`
-	Command  string `toml:"command"`
+	Path        string    `toml:"path"`
+	Shortcut    string    `toml:"shortcut"` // One or more keys, e.g. "gw"
+	Aliases     []string  `toml:"aliases"`  // Further names, e.g. ["api", "server"]
+	Command     string    `toml:"command"`
+	Description string    `toml:"description"`
+	Tags        []string  `toml:"tags"`
+	Pinned      bool      `toml:"pinned"`   // Always listed first, in configuration order
+	ID          int       `toml:"id"`       // Stable number (stable_ids), allocated when 0
+	Glob        string    `toml:"glob"`     // Generator: one destination per matching directory
+	Scan        *ScanSpec `toml:"scan"`     // Generator: directories found below a root
+	Label       string    `toml:"label"`    // Label template of a generator, e.g. "{name}"
+	Provider    string    `toml:"provider"` // Provider: command printing destinations
+	TTL         string    `toml:"ttl"`      // How long provider results are reused, e.g. "5m"
+	Timeout     string    `toml:"timeout"`  // Time limit of a provider, e.g. "3s"
+	Scope       string    `toml:"-"`        // Scope of a destination from a .goto.toml file
+	Source      string    `toml:"-"`        // The .goto.toml file of a local destination
+	Order       int       `toml:"-"`        // Position in the configuration files, for the config order
 }
 
 // HistoryEntry represents a history entry with timestamp
 type HistoryEntry struct {
-	Label    string    `json:"label"`
-	LastUsed time.Time `json:"last_used"`
+	Label    string         `json:"label"`
+	LastUsed time.Time      `json:"last_used"`
+	Visits   int            `json:"visits,omitempty"`   // Number of visits, for the frecency order
+	Subpaths map[string]int `json:"subpaths,omitempty"` // Visits beneath the destination (subpath_counts)
 }
 
 // Config represents the TOML configuration
 type Config map[string]Destination
 
+// Settings holds the settings sections of the configuration file
+type Settings struct {
+	General GeneralSettings     // [settings]
+	Keys    map[string][]string // [keys] menu action -> key names
+	Theme   ThemeConfig         // [theme]
+}
+
+// GeneralSettings represents the [settings] section of the configuration file
+type GeneralSettings struct {
+	Columns          []string `toml:"columns"`           // Columns of the menu and --list
+	HistoryColumns   []string `toml:"history_columns"`   // Columns of --history
+	LabelWidth       int      `toml:"label_width"`       // Maximum width of the label column
+	Status           *bool    `toml:"status"`            // Check the status of destinations (default: true)
+	StatusTimeout    string   `toml:"status_timeout"`    // Time budget for status checks, e.g. "300ms"
+	GitStatus        bool     `toml:"git_status"`        // Show dirty and ahead/behind state of git repositories
+	DigitTimeout     string   `toml:"digit_timeout"`     // Wait for more digits in cursor mode, e.g. "700ms"
+	Language         string   `toml:"language"`          // Language of the messages, e.g. "ja" (overridden by --lang)
+	SubpathCounts    bool     `toml:"subpath_counts"`    // Count visits beneath destinations to suggest frequent subpaths
+	NestedShell      string   `toml:"nested_shell"`      // goto inside a goto shell: "nest", "warn" or "replace"
+	LocalConfigs     *bool    `toml:"local_configs"`     // Merge .goto.toml files above the current directory (default: true)
+	ScanCacheTTL     string   `toml:"scan_cache_ttl"`    // Reuse glob and scan expansions for this long, e.g. "5m"
+	PluginPrecedence string   `toml:"plugin_precedence"` // "destinations" (default), "plugins" or "off"
+	Sort             string   `toml:"sort"`              // Order of the menu: history (default), frecency, alphabetical or config
+	StableIDs        bool     `toml:"stable_ids"`        // Number destinations by a stored ID instead of their position
+	GroupByTag       bool     `toml:"group_by_tag"`      // Show the menu in groups under their first tag
+}
+
+// statusEnabled reports whether destination status checks are enabled
+func (g GeneralSettings) statusEnabled() bool {
+	return g.Status == nil || *g.Status
+}
+
+// statusTimeout returns the time budget for status checks
+func (g GeneralSettings) statusTimeout() time.Duration {
+	if d, err := time.ParseDuration(g.StatusTimeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultStatusTimeout
+}
+
+// digitTimeout returns how long cursor mode waits for another digit of a number
+func (g GeneralSettings) digitTimeout() time.Duration {
+	if d, err := time.ParseDuration(g.DigitTimeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultDigitTimeout
+}
+
+// settingsSections lists the table names that hold settings instead of destinations
+var settingsSections = map[string]bool{
+	"settings": true,
+	"keys":     true,
+	"theme":    true,
+}
+
+// appSettings holds the active [settings] section
+var appSettings = defaultGeneralSettings()
+
 // History represents the JSON history data
 type History struct {
 	Entries []HistoryEntry `json:"entries"`
+	IDs     map[string]int `json:"ids,omitempty"` // Stable IDs by label (stable_ids)
 }
 
 // AppConfig holds application configuration
@@ -45,21 +121,90 @@ type AppConfig struct {
 	ConfigFile      string
 	HistoryFile     string
 	InteractiveMode string
+	Pick            bool     // goto pick: print the chosen destination instead of opening it
+	PickLabel       bool     // goto pick --label: print the label instead of the path
+	KeyList         string   // --keys: keys played back to the menu, e.g. "down,down,enter"
+	KeyFile         string   // --keys-file: key script played back to the menu
+	Snapshots       bool     // --snapshots: print every frame during playback
+	Language        string   // --lang: language of the messages
+	Profile         string   // --profile: profile bundling the configuration and history files
+	TagFilter       string   // --tag: only show destinations matching the filter, e.g. "work,!archived"
+	Sort            string   // --sort: order of the destinations, overriding the sort setting
+	Complete        bool     // --complete: print completion candidates
+	CompleteWords   []string // Words after --complete; the last one is being completed
 	FilteredArgs    []string
 }
 
 func main() {
-	// Initialize language support
-	initializeLanguage()
-
 	// Parse command line arguments and get configuration
 	appConfig := parseCommandLineArgs()
 
-	// Get configuration file path
-	tomlFile := getConfigFilePath(appConfig.ConfigFile)
+	// Initialize language support
+	initializeLanguage(appConfig.Language)
+
+	// In pick mode the menu is drawn on the terminal and stdout receives the result
+	var pickOut *os.File
+	if appConfig.Pick && !appConfig.Complete {
+		pickOut = redirectToTerminal()
+	}
+
+	// Profiles are managed before any configuration is loaded
+	if len(appConfig.FilteredArgs) > 0 && appConfig.FilteredArgs[0] == profileCommand && !appConfig.Complete {
+		runProfileCommand(appConfig.FilteredArgs[1:], appConfig)
+	}
+
+	// Moving the legacy files must not load (or create) them first
+	if len(appConfig.FilteredArgs) > 0 && appConfig.FilteredArgs[0] == migratePathsCommand && !appConfig.Complete {
+		migratePaths()
+	}
+
+	// Resolve the configuration and history files once; everything below uses them
+	resolveProfileFiles(&appConfig)
+	tomlFile := appConfig.ConfigFile
+
+	// Reverse lookup reads only the configuration and cached expansions, so it
+	// stays fast for prompts
+	if len(appConfig.FilteredArgs) > 0 && appConfig.FilteredArgs[0] == "which" && !appConfig.Complete {
+		cachedExpansionsOnly = true
+		runWhich(tomlFile, appConfig.FilteredArgs[1:])
+	}
+
+	// Trust is managed without loading the menu
+	if len(appConfig.FilteredArgs) > 0 && !appConfig.Complete {
+		if command := appConfig.FilteredArgs[0]; command == trustCommand || command == untrustCommand {
+			runTrustCommand(command, appConfig.FilteredArgs[1:], tomlFile)
+		}
+	}
+
+	// Completion must not wait for directory scans or providers
+	preferCachedExpansions = appConfig.Complete
 
 	// Load and validate configuration
+	applySortOption(appConfig)
 	entries, shortcutMap := loadAndValidateConfig(tomlFile, appConfig.HistoryFile)
+	entries, shortcutMap = applyTagFilter(entries, shortcutMap, appConfig)
+
+	// Print completion candidates for the shell completion scripts
+	if appConfig.Complete {
+		showCompletions(entries, appConfig)
+		return
+	}
+
+	if appConfig.Pick {
+		runPickMode(pickOut, entries, shortcutMap, tomlFile, appConfig)
+		return
+	}
+
+	// Play back scripted keys to the menu on a virtual screen
+	if appConfig.KeyList != "" || appConfig.KeyFile != "" {
+		keys, err := loadKeyScript(appConfig.KeyList, appConfig.KeyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", messages.ErrorInvalidKeyScript, err)
+			os.Exit(1)
+		}
+		runKeyPlayback(entries, shortcutMap, keys, appConfig.Snapshots)
+		return
+	}
 
 	// Handle command line arguments
 	if len(appConfig.FilteredArgs) > 0 {
@@ -68,13 +213,26 @@ func main() {
 	}
 
 	// Run interactive mode
-	runInteractiveMode(entries, shortcutMap, tomlFile, appConfig.InteractiveMode)
+	runInteractiveMode(entries, shortcutMap, tomlFile, appConfig.HistoryFile, appConfig.InteractiveMode)
 }
 
-// initializeLanguage initializes language support
-func initializeLanguage() {
-	currentLanguage = detectLanguage()
-	messages = getMessages(currentLanguage)
+// languageFixed is set when --lang is given, so the language setting is ignored
+var languageFixed bool
+
+// initializeLanguage initializes language support from --lang or the environment
+func initializeLanguage(lang string) {
+	setLanguage(detectLanguage())
+	if lang == "" {
+		return
+	}
+
+	resolved, ok := resolveLanguage(lang)
+	if !ok {
+		fmt.Fprintf(os.Stderr, messages.ErrorUnknownLanguage+"\n", lang, strings.Join(availableLanguages(), ", "))
+		os.Exit(1)
+	}
+	setLanguage(resolved)
+	languageFixed = true
 }
 
 // parseCommandLineArgs parses command line arguments and returns configuration
@@ -90,42 +248,21 @@ func parseCommandLineArgs() AppConfig {
 	args := os.Args[1:]
 	config.FilteredArgs = []string{}
 
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-
-		if arg == "--config-file" && i+1 < len(args) {
-			config.ConfigFile = args[i+1]
-			i++ // Skip the next argument as it's the file path
-		} else if arg == "--history-file" && i+1 < len(args) {
-			config.HistoryFile = args[i+1]
-			i++ // Skip the next argument as it's the file path
-		} else if arg == "-c" {
-			config.InteractiveMode = "cursor"
-		} else if arg == "-l" {
-			config.InteractiveMode = "label"
-		} else {
-			config.FilteredArgs = append(config.FilteredArgs, arg)
+	// goto --complete WORDS... CURRENT: options typed before the word being
+	// completed (e.g. --config-file) apply to the completion
+	if args[0] == "--complete" {
+		config.Complete = true
+		config.CompleteWords = args[1:]
+		if len(args) > 2 {
+			parseOptions(&config, args[1:len(args)-1])
 		}
+		return config
 	}
 
+	parseOptions(&config, args)
 	return config
 }
 
-// getConfigFilePath returns the configuration file path
-func getConfigFilePath(customConfigFile string) string {
-	if customConfigFile != "" {
-		return customConfigFile
-	}
-
-	usr, err := user.Current()
-	if err != nil {
-		fmt.Printf("%s %v\n", messages.ErrorGettingUser, err)
-		os.Exit(1)
-	}
-
-	return filepath.Join(usr.HomeDir, ".goto.toml")
-}
-
 // loadAndValidateConfig loads and validates configuration, returns entries and shortcut map
 func loadAndValidateConfig(tomlFile, customHistoryFile string) ([]Entry, map[string]int) {
 	// Create default config if it doesn't exist
@@ -134,7 +271,13 @@ func loadAndValidateConfig(tomlFile, customHistoryFile string) ([]Entry, map[str
 	}
 
 	// Load configuration
-	config, err := loadConfig(tomlFile)
+	config, settings, err := loadConfigWithSettings(tomlFile)
+	if err == nil {
+		err = applySettings(settings)
+	}
+	if err == nil {
+		err = validateIDs(config)
+	}
 	if err != nil {
 		fmt.Printf("%s\n", messages.ErrorReadingConfig)
 		fmt.Printf("📁 %s: %s\n", messages.ConfigFile, tomlFile)
@@ -142,6 +285,8 @@ func loadAndValidateConfig(tomlFile, customHistoryFile string) ([]Entry, map[str
 		fmt.Printf("💡 %s\n", messages.ConfigFixSuggestion)
 		os.Exit(1)
 	}
+	mergeLocalDestinations(config, tomlFile)
+	warnLocalShortcutClashes()
 
 	// Get entries sorted by history and shortcuts
 	entries := getEntriesFromConfig(config, customHistoryFile)
@@ -161,7 +306,8 @@ func handleCommandLineArguments(filteredArgs []string, entries []Entry, shortcut
 
 	// Handle help option
 	if arg == "-h" || arg == "--help" || arg == "help" {
-		showHelp()
+		showHelp(tomlFile, customHistoryFile)
+		printPlugins(entries, shortcutMap)
 		os.Exit(0)
 	}
 
@@ -171,9 +317,9 @@ func handleCommandLineArguments(filteredArgs []string, entries []Entry, shortcut
 		os.Exit(0)
 	}
 
-	// Handle completion option for bash/zsh tab completion
-	if arg == "--complete" {
-		showCompletions(entries)
+	// Handle completion script generation
+	if arg == "completion" {
+		printCompletionScript(filteredArgs[1:])
 		os.Exit(0)
 	}
 
@@ -185,19 +331,39 @@ func handleCommandLineArguments(filteredArgs []string, entries []Entry, shortcut
 
 	// Handle list option
 	if arg == "--list" {
-		showList(entries)
+		if len(filteredArgs) > 1 && filteredArgs[1] == jsonOption {
+			showListJSON(entries)
+		} else {
+			showList(entries)
+		}
 		os.Exit(0)
 	}
 
+	// Handle configuration check
+	if arg == checkCommand {
+		runCheck(entries)
+	}
+
 	// Handle list-label option
 	if arg == "--list-label" {
 		showListLabel(entries)
 		os.Exit(0)
 	}
 
+	// Handle going back through the navigation stack
+	if arg == backCommand || arg == backStepsCommand {
+		navigateBack(filteredArgs, entries, tomlFile, customHistoryFile)
+	}
+
+	// Handle stack listing
+	if arg == stackCommand {
+		showStack(customHistoryFile)
+		os.Exit(0)
+	}
+
 	// Handle add option
 	if arg == "--add" {
-		success := addCurrentPathToConfig(tomlFile)
+		success := addCurrentPathToConfig(tomlFile, customHistoryFile)
 		if success {
 			os.Exit(0)
 		} else {
@@ -205,6 +371,9 @@ func handleCommandLineArguments(filteredArgs []string, entries []Entry, shortcut
 		}
 	}
 
+	// Run a goto-NAME plugin unless a destination wins
+	runPluginIfAny(filteredArgs, entries, shortcutMap, tomlFile, customHistoryFile)
+
 	// Find destination by argument
 	handleDestinationNavigation(arg, entries, shortcutMap, tomlFile, customHistoryFile)
 }
@@ -213,30 +382,48 @@ func handleCommandLineArguments(filteredArgs []string, entries []Entry, shortcut
 func handleDestinationNavigation(arg string, entries []Entry, shortcutMap map[string]int, tomlFile, customHistoryFile string) {
 	targetDir, command, label := findDestinationByArg(arg, entries, shortcutMap)
 
-	if targetDir == "" {
-		fmt.Printf(messages.DestinationNotFound, arg)
-		fmt.Println("\n📋 Available destinations:")
-		for _, entry := range entries {
-			shortcutStr := ""
-			if entry.Shortcut != "" {
-				shortcutStr = fmt.Sprintf(" (%s)", entry.Shortcut)
+	// "label/sub/dir" navigates beneath the destination
+	subpath := ""
+	if head, rest, ok := splitSubpath(arg); targetDir == "" && ok {
+		if targetDir, command, label = findDestinationByArg(head, entries, shortcutMap); targetDir != "" {
+			dir, err := resolveSubpath(targetDir, rest)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
 			}
-			expandedPath := expandPath(entry.Path)
-			fmt.Printf("  • %s%s → %s\n", entry.Label, shortcutStr, expandedPath)
+			targetDir, subpath = dir, cleanSubpath(rest)
 		}
+	}
+
+	if targetDir == "" {
+		fmt.Printf(messages.DestinationNotFound, arg)
+		fmt.Printf("\n%s\n", messages.AvailableDestinationsList)
+		printEntryTable(entries)
 		os.Exit(1)
 	}
 
-	fmt.Printf("%s %s\n", messages.FoundDestination, label)
-	success := openNewShell(targetDir, command, label)
-	if success {
-		// Update history
-		if label != "" {
-			err := UpdateHistory(tomlFile, label, customHistoryFile)
-			if err != nil {
-				fmt.Printf("%s %v\n", messages.WarningFailedToUpdateHistory, err)
-			}
+	displayLabel := label
+	if subpath != "" {
+		displayLabel = label + "/" + subpath
+	}
+	fmt.Printf("%s %s\n", messages.FoundDestination, displayLabel)
+	if !directoryExists(targetDir) {
+		os.Exit(1)
+	}
+	// Update history before the shell opens, as it may hand its level over
+	// and exit; a subpath credits its destination
+	if label != "" {
+		err := UpdateSubpathHistory(tomlFile, label, subpath, customHistoryFile)
+		if err != nil {
+			fmt.Printf("%s %v\n", messages.WarningFailedToUpdateHistory, err)
 		}
+	}
+
+	warnPositionalCommand(arg, entries, command, label)
+	command = trustedCommand(entries, label, command)
+	pushOrigin(entries, targetDir, customHistoryFile)
+	success := openNewShell(targetDir, command, displayLabel)
+	if success {
 		os.Exit(0)
 	} else {
 		os.Exit(1)
@@ -244,11 +431,12 @@ func handleDestinationNavigation(arg string, entries []Entry, shortcutMap map[st
 }
 
 // runInteractiveMode runs the interactive mode
-func runInteractiveMode(entries []Entry, shortcutMap map[string]int, tomlFile, interactiveMode string) {
+func runInteractiveMode(entries []Entry, shortcutMap map[string]int, tomlFile, historyFile, interactiveMode string) {
+	annotateStatuses(entries)
 	targetDir, command, label := getUserChoice(entries, shortcutMap, tomlFile, interactiveMode)
 
 	if targetDir == "ADD_CURRENT" {
-		success := addCurrentPathToConfig(tomlFile)
+		success := addCurrentPathToConfig(tomlFile, historyFile)
 		if success {
 			os.Exit(0)
 		} else {
@@ -262,14 +450,19 @@ func runInteractiveMode(entries []Entry, shortcutMap map[string]int, tomlFile, i
 	}
 
 	// Update history
+	if !directoryExists(targetDir) {
+		os.Exit(1)
+	}
 	if label != "" {
-		err := UpdateHistory(tomlFile, label, "")
+		err := UpdateHistory(tomlFile, label, historyFile)
 		if err != nil {
 			fmt.Printf("%s %v\n", messages.WarningFailedToUpdateHistory, err)
 		}
 	}
 
 	// Open the selected destination
+	command = trustedCommand(entries, label, command)
+	pushOrigin(entries, targetDir, historyFile)
 	success := openNewShell(targetDir, command, label)
 	if success {
 		os.Exit(0)
@@ -280,10 +473,39 @@ func runInteractiveMode(entries []Entry, shortcutMap map[string]int, tomlFile, i
 
 // Entry represents a configuration entry with label
 type Entry struct {
-	Label    string
-	Path     string
-	Shortcut string
-	Command  string
+	Label       string
+	Path        string
+	Shortcut    string
+	Aliases     []string
+	Command     string
+	Description string
+	Tags        []string
+	Scope       string      // Scope of a local destination, empty for the configuration file
+	Source      string      // The .goto.toml file of a local destination
+	Pinned      bool        // Listed first, in configuration order
+	ID          int         // Stable number when stable_ids is enabled
+	Order       int         // Position in the configuration files
+	LastUsed    time.Time   // Zero when the entry has no history
+	Visits      int         // Number of visits recorded in the history
+	Status      EntryStatus // Filled in by annotateStatuses
+}
+
+// newEntry creates an entry from a destination in the configuration
+func newEntry(label string, dest Destination) Entry {
+	return Entry{
+		Label:       label,
+		Path:        dest.Path,
+		Shortcut:    dest.Shortcut,
+		Aliases:     dest.Aliases,
+		Command:     dest.Command,
+		Description: dest.Description,
+		Tags:        dest.Tags,
+		Scope:       dest.Scope,
+		Source:      dest.Source,
+		Pinned:      dest.Pinned,
+		ID:          dest.ID,
+		Order:       dest.Order,
+	}
 }
 
 func buildShortcutMap(entries []Entry) map[string]int {
@@ -315,34 +537,55 @@ func expandPath(path string) string {
 func getUserChoice(entries []Entry, shortcutMap map[string]int, tomlFile string, interactiveMode string) (string, string, string) {
 	// インタラクティブモードに基づいて分岐
 	switch interactiveMode {
-	case "cursor":
-		return getUserChoiceCursorMode(entries, shortcutMap, tomlFile)
 	case "label":
 		return getUserChoiceCmdMode(entries, shortcutMap, tomlFile)
-	default: // "auto"
-		return getUserChoiceCursorMode(entries, shortcutMap, tomlFile) // デフォルトでカーソルモード
+	default: // "auto", "cursor"
+		// The cursor menu reads raw key presses, which needs a terminal;
+		// with redirected input or output the line-based mode is used instead
+		if !terminalAvailable() {
+			return getUserChoiceCmdMode(entries, shortcutMap, tomlFile)
+		}
+		return getUserChoiceCursorMode(entries, shortcutMap, tomlFile)
 	}
 }
 
+// terminalAvailable reports whether both standard input and output are terminals
+func terminalAvailable() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 // 共通のエントリー表示処理
-func displayEntries(entries []Entry, selectedIndex int, cursorMode bool) {
-	// ターミナル横幅取得
-	termWidth := 80
-	termHeight := 24
-	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
-		termWidth = w
-		termHeight = h
+// view lists the indices of entries to show (nil shows all entries), and
+// selectedIndex is a position in view; len(view) selects the Exit line.
+func displayEntries(entries []Entry, view []int, selectedIndex int, cursorMode bool) {
+	termWidth, termHeight := terminalSize()
+	writeEntries(os.Stdout, entries, view, selectedIndex, cursorMode, termWidth, termHeight)
+}
+
+// writeEntries writes the entry list for a screen of the given size
+func writeEntries(w io.Writer, entries []Entry, view []int, selectedIndex int, cursorMode bool, termWidth, termHeight int) {
+	if view == nil {
+		view = make([]int, len(entries))
+		for i := range entries {
+			view[i] = i
+		}
+		if appSettings.GroupByTag {
+			groupByTag(entries, view)
+		}
 	}
 
 	// カーソルモードの場合、画面に収まる行数を計算
-	maxDisplayEntries := len(entries)
+	maxDisplayEntries := len(view)
 	if cursorMode {
 		// ヘッダー(2行) + フッター(3行) + Exit(1行) + マージン(2行) = 8行を除く
 		availableLines := termHeight - 8
+		if appSettings.GroupByTag {
+			availableLines -= countGroups(entries, view) // Group headers take a line each
+		}
 		if availableLines < 3 {
 			availableLines = 3 // 最低3行は確保
 		}
-		if len(entries) > availableLines {
+		if len(view) > availableLines {
 			maxDisplayEntries = availableLines
 		}
 	}
@@ -351,7 +594,7 @@ func displayEntries(entries []Entry, selectedIndex int, cursorMode bool) {
 	displayEnd := maxDisplayEntries
 
 	// カーソルモードで選択項目が表示範囲外の場合、表示範囲を調整
-	if cursorMode && maxDisplayEntries < len(entries) {
+	if cursorMode && maxDisplayEntries < len(view) {
 		if selectedIndex >= maxDisplayEntries {
 			// 選択項目が表示範囲の下にある場合
 			displayStart = selectedIndex - maxDisplayEntries + 1
@@ -359,69 +602,72 @@ func displayEntries(entries []Entry, selectedIndex int, cursorMode bool) {
 				displayStart = 0
 			}
 			displayEnd = displayStart + maxDisplayEntries
-			if displayEnd > len(entries) {
-				displayEnd = len(entries)
+			if displayEnd > len(view) {
+				displayEnd = len(view)
 				displayStart = displayEnd - maxDisplayEntries
 			}
 		}
 	}
 
-	// エントリーの表示
-	for i := displayStart; i < displayEnd; i++ {
-		entry := entries[i]
-		expandedPath := expandPath(entry.Path)
-		shortcutStr := ""
-		if entry.Shortcut != "" {
-			shortcutStr = fmt.Sprintf(" (%s)", entry.Shortcut)
-		}
+	// 全エントリーで列幅を揃えるため、表示範囲外の行もレイアウトする
+	rows := make([]tableRow, 0, len(view)+1)
+	for pos, i := range view {
+		row := entryRow(entries[i], entryNumber(entries, i))
+		row.selected = cursorMode && pos == selectedIndex
+		rows = append(rows, row)
+	}
+	rows = append(rows, tableRow{
+		cells: map[string]tableCell{
+			ColumnNumber: {text: "0"},
+			ColumnLabel:  {text: messages.ExitLabel},
+		},
+		selected: cursorMode && selectedIndex == len(view),
+	})
+
+	layout := newTableLayout(appSettings.Columns, false)
+	layout.width = termWidth
+	layout.cursorMode = cursorMode
+	lines := layout.render(rows)
 
-		// 表示番号の決定（10以上は"-"で表示）
-		var numStr string
-		if i+1 < 10 {
-			numStr = fmt.Sprintf("%d", i+1)
-		} else {
-			numStr = " "
+	// エントリーの表示
+	for pos := displayStart; pos < displayEnd; pos++ {
+		if appSettings.GroupByTag {
+			group := tagGroup(entries[view[pos]])
+			if pos == displayStart || group != tagGroup(entries[view[pos-1]]) {
+				fmt.Fprintln(w, groupHeader(group))
+			}
 		}
+		fmt.Fprintln(w, lines[pos])
+	}
 
-		// フォーマット: 数字 ラベル (ショートカットキー) → パス
-		// ラベルを20文字に左寄せ
-		labelWithShortcut := entry.Label + shortcutStr
-		formattedLabel := fmt.Sprintf("%-20s", labelWithShortcut)
-		if len([]rune(labelWithShortcut)) > 20 {
-			// 20文字を超える場合は切り詰める
-			runes := []rune(labelWithShortcut)
-			formattedLabel = string(runes[:20])
-		}
+	// 省略表示の情報
+	if cursorMode && maxDisplayEntries < len(view) {
+		omittedCount := len(view) - maxDisplayEntries
+		fmt.Fprintf(w, messages.MoreEntriesHidden+"\n", omittedCount)
+	}
 
-		prefix := fmt.Sprintf("%s %s → ", numStr, formattedLabel)
-		maxPathLen := termWidth - len([]rune(prefix))
-		pathStr := expandedPath
-		if maxPathLen > 8 && len([]rune(expandedPath)) > maxPathLen {
-			pathStr = shortenPathMiddle(expandedPath, maxPathLen)
-		}
+	// Exitの表示
+	fmt.Fprintln(w, lines[len(lines)-1])
+}
 
-		// カーソルモードの場合、選択中の項目をハイライト
-		if cursorMode && i == selectedIndex {
-			fmt.Printf("\033[47;30m%s%s\033[0m\n", prefix, pathStr) // 白背景でハイライト
-		} else {
-			fmt.Printf("%s%s\n", prefix, pathStr)
-		}
+// selectedLine returns the line of the selected entry in cursor mode.
+// Without colours the selection is shown with a marker instead of a highlight.
+func selectedLine(line string) string {
+	if !theme.Enabled {
+		return cursorMarker(true, true) + line
 	}
+	return theme.paint(theme.Selection, line)
+}
 
-	// 省略表示の情報
-	if cursorMode && maxDisplayEntries < len(entries) {
-		omittedCount := len(entries) - maxDisplayEntries
-		fmt.Printf("... (%d more entries hidden)\n", omittedCount)
+// cursorMarker returns the selection marker column shown in cursor mode without colours
+func cursorMarker(cursorMode, selected bool) string {
+	if !cursorMode || theme.Enabled {
+		return ""
 	}
-
-	// print "0 Exit"
-	exitPrefix := "0 Exit"
-	exitIndex := len(entries) // Exitは最後のインデックス
-	if cursorMode && selectedIndex == exitIndex {
-		fmt.Printf("\033[47;30m%s\033[0m\n", exitPrefix) // 白背景でハイライト
-	} else {
-		fmt.Printf("%s\n", exitPrefix)
+	if selected {
+		return "> "
 	}
+	return "  "
 }
 
 // 共通の入力解析処理
@@ -440,8 +686,8 @@ func parseUserInput(choice string, entries []Entry, shortcutMap map[string]int)
 	index := 0
 
 	// Check if it's a number
-	if num, err := strconv.Atoi(choice); err == nil {
-		index = num
+	if _, err := strconv.Atoi(choice); err == nil {
+		index = findEntryByNumber(entries, choice) + 1
 	} else if shortcutIndex, exists := shortcutMap[choice]; exists {
 		// Check if it's a shortcut
 		index = shortcutIndex
@@ -453,6 +699,22 @@ func parseUserInput(choice string, entries []Entry, shortcutMap map[string]int)
 				break
 			}
 		}
+		// Aliases work like labels
+		if alias, ok := findEntryByAlias(entries, choice); ok && index == 0 {
+			for i, entry := range entries {
+				if entry.Label == alias.Label {
+					index = i + 1
+				}
+			}
+		}
+		// Local destinations are also found without their scope
+		if local, ok := findLocalByName(entries, choice); ok && index == 0 {
+			for i, entry := range entries {
+				if entry.Label == local.Label {
+					index = i + 1
+				}
+			}
+		}
 	}
 
 	if index >= 1 && index <= len(entries) {
@@ -464,166 +726,29 @@ func parseUserInput(choice string, entries []Entry, shortcutMap map[string]int)
 	return "", "", "" // Invalid input
 }
 
-// カーソルモードでのユーザー選択
-func getUserChoiceCursorMode(entries []Entry, shortcutMap map[string]int, tomlFile string) (string, string, string) {
-	selectedIndex := 0
-	inputBuffer := "" // 複数文字入力用のバッファ
-
-	// 初期表示
-	redrawCursorMode(entries, selectedIndex)
-	for {
-		// Raw modeで入力を読み取り
-		oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
-		if err != nil {
-			fmt.Printf("Error entering raw mode: %v\n", err)
-			return "", "", ""
-		}
-		buffer := make([]byte, 4)
-		n, err := os.Stdin.Read(buffer)
-		term.Restore(int(os.Stdin.Fd()), oldState)
-
-		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			return "", "", ""
-		}
-
-		redraw := false
-
-		// キー入力を解析
-		if n == 1 {
-			switch buffer[0] {
-			case 13: // Enter
-				if selectedIndex == len(entries) {
-					// Exitが選択された場合
-					fmt.Printf("\n%s\n", messages.OperationCancelled)
-					return "", "", ""
-				}
-				entry := entries[selectedIndex]
-				expandedPath := expandPath(entry.Path)
-				return expandedPath, entry.Command, entry.Label
-			case 27: // Escape
-				// ラベル入力モードに切り替え
-				return getUserChoiceCmdMode(entries, shortcutMap, tomlFile)
-			case '+':
-				return "ADD_CURRENT", "", ""
-			case '0': // 0キーでExit
-				fmt.Printf("\n%s\n", messages.OperationCancelled)
-				return "", "", ""
-			case '?': // ?キーでヘルプ表示
-				showInteractiveHelp()
-				// 画面をクリアして再表示
-				redraw = true
-				continue
-			case 'j': // j キーで下移動 (Vim風)
-				inputBuffer = "" // バッファをクリア
-				if selectedIndex < len(entries) {
-					selectedIndex++
-					redraw = true
-				}
-			case 'k': // k キーで上移動 (Vim風)
-				inputBuffer = "" // バッファをクリア
-				if selectedIndex > 0 {
-					selectedIndex--
-					redraw = true
-				}
-			default:
-				// 数字キー (0-9) またはアルファベットキーの場合
-				if (buffer[0] >= '0' && buffer[0] <= '9') || (buffer[0] >= 'a' && buffer[0] <= 'z') || (buffer[0] >= 'A' && buffer[0] <= 'Z') {
-					inputChar := string(buffer[0])
-
-					// j/k は上で処理済みなのでスキップ
-					if inputChar == "j" || inputChar == "k" {
-						break
-					}
-
-					// 数字の場合、バッファに追加
-					if buffer[0] >= '0' && buffer[0] <= '9' {
-						inputBuffer += inputChar
-						// 入力された数字が有効な範囲内かチェック
-						if num, err := strconv.Atoi(inputBuffer); err == nil {
-							if num >= 1 && num <= len(entries) {
-								// 有効な番号の場合、即座に決定
-								entry := entries[num-1]
-								expandedPath := expandPath(entry.Path)
-								return expandedPath, entry.Command, entry.Label
-							} else if num > len(entries) {
-								// 範囲外の場合、バッファをクリア
-								inputBuffer = ""
-							}
-						}
-					} else {
-						// ショートカットキーの場合、バッファをクリアして即座に実行
-						inputBuffer = ""
-						if shortcutIndex, exists := shortcutMap[inputChar]; exists {
-							entry := entries[shortcutIndex-1]
-							expandedPath := expandPath(entry.Path)
-							return expandedPath, entry.Command, entry.Label
-						}
-					}
-				} else {
-					// その他のキーが押された場合、バッファをクリア
-					inputBuffer = ""
-				}
-			}
-		} else if n >= 3 && buffer[0] == 27 && buffer[1] == '[' {
-			switch buffer[2] {
-			case 'A': // Up arrow
-				inputBuffer = "" // バッファをクリア
-				if selectedIndex > 0 {
-					selectedIndex--
-					redraw = true
-				}
-			case 'B': // Down arrow
-				inputBuffer = "" // バッファをクリア
-				if selectedIndex < len(entries) {
-					selectedIndex++
-					redraw = true
-				}
-			}
-		}
-
-		// 画面の再描画
-		if redraw {
-			redrawCursorMode(entries, selectedIndex)
-		}
-	}
-}
-
-// カーソルモードの画面再描画
-func redrawCursorMode(entries []Entry, selectedIndex int) {
-	// より効率的な再描画: 画面全体をクリアしてから再描画
-	fmt.Print("\033[2J\033[H") // 画面をクリアしてカーソルを左上に移動
-
-	// ヘッダーを表示
-	PrintWhiteBackgroundLine(messages.AvailableDestinations)
-	fmt.Println() // ヘッダーの後に改行
-
-	// エントリーリストを再表示
-	displayEntries(entries, selectedIndex, true)
-
-	// 横線を表示
-	PrintHorzontalLine("-")
-	// フッターメッセージを更新
-	fmt.Println(messages.InteractiveHelp)
-	fmt.Printf("%s\n", messages.CursorModeHint)
-}
-
 // コマンド（ラベル）入力モードでのユーザー選択
 func getUserChoiceCmdMode(entries []Entry, shortcutMap map[string]int, tomlFile string) (string, string, string) {
+	interactive := terminalAvailable()
+	// One reader for all attempts: with piped input it buffers the lines after
+	// an invalid choice
+	reader := bufio.NewReader(os.Stdin)
 	for {
-		// 画面をクリア
-		fmt.Print("\033[2J\033[H")
-		PrintWhiteBackgroundLine(messages.AvailableDestinations)
+		// 画面をクリア（端末でない場合は一覧をそのまま出力）
+		if interactive {
+			fmt.Print("\033[2J\033[H")
+		}
+		PrintHeaderLine(menuTitle())
 		fmt.Println()
-		displayEntries(entries, 0, false)
-		PrintWhiteBackgroundLine(messages.InteractiveHelp)
+		displayEntries(entries, nil, 0, false)
+		PrintHeaderLine(messages.InteractiveHelp)
 		fmt.Println()
 		fmt.Printf("%s\n", messages.EnterChoice)
-		fmt.Printf("%s\n", messages.BackToCursorModeHint)
+		if interactive {
+			fmt.Printf("%s\n", messages.BackToCursorModeHint)
+		}
 		fmt.Printf("%s ", messages.EnterChoicePrompt)
 
 		// 通常の入力モード
-		reader := bufio.NewReader(os.Stdin)
 		choice, err := reader.ReadString('\n')
 		if err != nil {
 			fmt.Printf("\n%s\n", messages.OperationCancelled)
@@ -634,12 +759,15 @@ func getUserChoiceCmdMode(entries []Entry, shortcutMap map[string]int, tomlFile
 
 		// 空の入力の場合、カーソルモードに切り替え
 		if choice == "" {
+			if !interactive {
+				continue
+			}
 			return getUserChoiceCursorMode(entries, shortcutMap, tomlFile)
 		}
 
 		// Check if user wants to show help
 		if choice == "?" {
-			showInteractiveHelp()
+			showInteractiveHelp(tomlFile)
 			continue
 		}
 
@@ -667,6 +795,19 @@ func getUserChoiceCmdMode(entries []Entry, shortcutMap map[string]int, tomlFile
 	}
 }
 
+// directoryExists reports whether the directory of a destination exists,
+// printing a message when it does not; URLs always exist
+func directoryExists(targetDir string) bool {
+	if IsURL(targetDir) {
+		return true
+	}
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		fmt.Printf("%s %s\n", messages.DirectoryNotExist, targetDir)
+		return false
+	}
+	return true
+}
+
 func openNewShell(targetDir, command, label string) bool {
 	// URLの場合はブラウザで開く
 	if IsURL(targetDir) {
@@ -677,22 +818,21 @@ func openNewShell(targetDir, command, label string) bool {
 
 		err := OpenURL(targetDir)
 		if err != nil {
-			fmt.Printf("Error opening URL: %v\n", err)
+			fmt.Printf("%s %v\n", messages.ErrorOpeningURL, err)
 			return false
 		}
 
-		fmt.Printf("✅ Opened URL in default browser: %s\n", targetDir)
+		fmt.Printf("%s %s\n", messages.OpenedURL, targetDir)
 		return true
 	}
 
-	// Check if directory exists
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-		fmt.Printf("%s %s\n", messages.DirectoryNotExist, targetDir)
+	if !directoryExists(targetDir) {
 		return false
 	}
+	handOffLevel(targetDir, command, label)
 
 	openShellMessage := fmt.Sprintf("%s %s", messages.OpeningShell, targetDir)
-	PrintWhiteBackgroundLine(openShellMessage)
+	PrintHeaderLine(openShellMessage)
 	fmt.Println()
 	if label != "" {
 		fmt.Printf("%s %s\n", messages.Destination, label)
@@ -711,12 +851,14 @@ func openNewShell(targetDir, command, label string) bool {
 		// Create a temporary startup script
 		tempScript := createTempScript(targetDir, command, shell)
 		defer os.Remove(tempScript)
+		warnNestedShell()
 
 		// Execute the temporary script
 		cmd := exec.Command("/bin/sh", tempScript)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
+		cmd.Env = shellEnvironment(label, os.Getpid())
 		cmd.Dir = targetDir
 
 		err := cmd.Run()
@@ -729,13 +871,15 @@ func openNewShell(targetDir, command, label string) bool {
 		fmt.Println(messages.TypeExitToReturn)
 		fmt.Println(strings.Repeat("=", 50))
 
-		fmt.Printf("%s %s\n", messages.YouAreNowIn, targetDir)
+		fmt.Printf("%s %s %s\n", messages.YouAreNowIn, targetDir, fmt.Sprintf(messages.ShellDepth, shellDepth()))
+		warnNestedShell()
 
 		// Start new shell with the target directory as working directory
 		cmd := exec.Command(shell)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
+		cmd.Env = shellEnvironment(label, os.Getpid())
 		cmd.Dir = targetDir // Set working directory for the new shell
 
 		err := cmd.Run()
@@ -745,6 +889,10 @@ func openNewShell(targetDir, command, label string) bool {
 		}
 	}
 
+	// The shell exited to hand its level over to another destination
+	if next, ok := takeHandoff(); ok {
+		return openNewShell(next.Dir, next.Command, next.Label)
+	}
 	return true
 }
 
@@ -756,6 +904,7 @@ func createTempScript(targetDir, command, shell string) string {
 	}
 
 	scriptContent := fmt.Sprintf(`#!/bin/sh
+rm -f "$0"
 cd "%s"
 echo "%s $(pwd)"
 echo "%s %s"
@@ -787,7 +936,7 @@ exec "%s"
 	return tempFile.Name()
 }
 
-func addCurrentPathToConfig(tomlFile string) bool {
+func addCurrentPathToConfig(tomlFile, historyFile string) bool {
 	currentDir, err := os.Getwd()
 	if err != nil {
 		fmt.Printf("%s %v\n", messages.ErrorGettingCurrentDir, err)
@@ -803,7 +952,7 @@ func addCurrentPathToConfig(tomlFile string) bool {
 		return false
 	}
 
-	entries := getEntriesFromConfig(config, "")
+	entries := getEntriesFromConfig(config, historyFile)
 	shortcutMap := buildShortcutMap(entries)
 
 	// フォルダ名をデフォルトラベルとして取得
@@ -880,9 +1029,9 @@ func addCurrentPathToConfig(tomlFile string) bool {
 
 func findDestinationByArg(arg string, entries []Entry, shortcutMap map[string]int) (string, string, string) {
 	// Check if it's a number
-	if num, err := strconv.Atoi(arg); err == nil {
-		if num >= 1 && num <= len(entries) {
-			entry := entries[num-1]
+	if _, err := strconv.Atoi(arg); err == nil {
+		if index := findEntryByNumber(entries, arg); index >= 0 {
+			entry := entries[index]
 			expandedPath := expandPath(entry.Path)
 			return expandedPath, entry.Command, entry.Label
 		}
@@ -904,6 +1053,16 @@ func findDestinationByArg(arg string, entries []Entry, shortcutMap map[string]in
 		}
 	}
 
+	// Check if it's an alias
+	if entry, ok := findEntryByAlias(entries, arg); ok {
+		return expandPath(entry.Path), entry.Command, entry.Label
+	}
+
+	// Check if it's the label of a local destination without its scope
+	if entry, ok := findLocalByName(entries, arg); ok {
+		return expandPath(entry.Path), entry.Command, entry.Label
+	}
+
 	return "", "", ""
 }
 
@@ -911,32 +1070,44 @@ func showVersion() {
 	fmt.Printf("%s version %s\n", AppName, Version)
 }
 
-func showHelp() {
-	// Get configuration file path for display
-	usr, err := user.Current()
-	configPath := "~/.goto.toml"
-	if err == nil {
-		configPath = filepath.Join(usr.HomeDir, ".goto.toml")
-	}
-
+func showHelp(tomlFile, historyFile string) {
 	fmt.Println(messages.NavigateDirectoriesQuickly)
-	fmt.Printf("\n%s %s\n", messages.ConfigurationFile, configPath)
+	fmt.Printf("\n%s %s\n", messages.ConfigurationFile, tomlFile)
+	fmt.Printf("%s %s\n", messages.HistoryFileInUse, historyFile)
+	if activeProfile != defaultProfile {
+		fmt.Printf("%s %s\n", messages.ProfileInUse, activeProfile)
+	}
 	fmt.Printf("\n%s\n", messages.Usage)
 	fmt.Printf("  goto                 %s\n", messages.ShowInteractiveMenu)
-	fmt.Printf("  goto -c              %s\n", "カーソル移動モードでインタラクティブメニューを表示")
-	fmt.Printf("  goto -l              %s\n", "ラベル入力モードでインタラクティブメニューを表示")
-	fmt.Printf("  goto --config-file FILE %s\n", "指定した設定ファイルを使用")
-	fmt.Printf("  goto --history-file FILE %s\n", "指定した履歴ファイルを使用")
+	fmt.Printf("  goto -c              %s\n", messages.HelpCursorMode)
+	fmt.Printf("  goto -l              %s\n", messages.HelpLabelMode)
+	fmt.Printf("  goto --config-file FILE %s\n", messages.HelpConfigFile)
+	fmt.Printf("  goto --history-file FILE %s\n", messages.HelpHistoryFile)
+	fmt.Printf("  goto --profile NAME  %s\n", messages.HelpProfile)
+	fmt.Printf("  goto profile list|use|new [NAME] %s\n", messages.HelpProfileCommand)
+	fmt.Printf("  goto migrate-paths   %s\n", messages.HelpMigratePaths)
 	fmt.Printf("  goto <number>        %s\n", messages.GoToDestinationByNumber)
 	fmt.Printf("  goto <label>         %s\n", messages.GoToDestinationByLabel)
 	fmt.Printf("  goto <shortcut>      %s\n", messages.GoToDestinationByShortcut)
 	fmt.Printf("  goto -h, --help      %s\n", messages.ShowHelpMessage)
 	fmt.Printf("  goto -v, --version   %s\n", messages.ShowVersionInfo)
 	fmt.Printf("  goto --complete      %s\n", messages.ShowCompletionCandidates)
+	fmt.Printf("  goto completion SHELL %s\n", messages.HelpCompletion)
 	fmt.Printf("  goto --history       %s\n", messages.ShowRecentUsageHistory)
-	fmt.Printf("  goto --list          %s\n", "履歴順でディレクトリ一覧を表示")
-	fmt.Printf("  goto --list-label    %s\n", "履歴順でラベル一覧を表示")
+	fmt.Printf("  goto --list [--json] %s\n", messages.HelpList)
+	fmt.Printf("  goto --tag FILTER    %s\n", messages.HelpTag)
+	fmt.Printf("  goto --sort ORDER    %s\n", messages.HelpSort)
+	fmt.Printf("  goto check           %s\n", messages.HelpCheck)
+	fmt.Printf("  goto --list-label    %s\n", messages.HelpListLabel)
+	fmt.Printf("  goto --lang LANG     %s\n", messages.HelpLang)
 	fmt.Printf("  goto --add           %s\n", messages.AddCurrentDirectoryToConfig)
+	fmt.Printf("  goto pick [--label]  %s\n", messages.PickDestination)
+	fmt.Printf("  goto which [--json] [PATH] %s\n", messages.HelpWhich)
+	fmt.Printf("  goto -, --back N     %s\n", messages.HelpBack)
+	fmt.Printf("  goto stack           %s\n", messages.HelpStack)
+	fmt.Printf("  goto trust [PATH]    %s\n", messages.HelpTrust)
+	fmt.Printf("  goto untrust [PATH]  %s\n", messages.HelpUntrust)
+	fmt.Printf("  goto --keys KEYS [--snapshots] %s\n", messages.PlayBackKeys)
 	fmt.Printf("\n%s\n", messages.Examples)
 	fmt.Printf("  goto 1              %s\n", messages.NavigateToFirstDest)
 	fmt.Printf("  goto Home           %s\n", messages.NavigateToHomeDest)
@@ -946,15 +1117,19 @@ func showHelp() {
 
 // showList displays all destinations sorted by history
 func showList(entries []Entry) {
-	for i, entry := range entries {
-		// Format: number. label (shortcut) → path
-		shortcutStr := ""
-		if entry.Shortcut != "" {
-			shortcutStr = fmt.Sprintf(" (%s)", entry.Shortcut)
-		}
+	annotateOutputStatuses(entries)
+	printEntryTable(entries)
+}
 
-		expandedPath := expandPath(entry.Path)
-		fmt.Printf("%2d. %s%s → %s\n", i+1, entry.Label, shortcutStr, expandedPath)
+// printEntryTable prints entries with their numbers using the configured columns
+func printEntryTable(entries []Entry) {
+	rows := make([]tableRow, 0, len(entries))
+	for i, entry := range entries {
+		rows = append(rows, entryRow(entry, entryNumber(entries, i)+"."))
+	}
+	layout := newTableLayout(appSettings.Columns, term.IsTerminal(int(os.Stdout.Fd())))
+	for _, line := range layout.render(rows) {
+		fmt.Println(line)
 	}
 }
 
@@ -964,10 +1139,3 @@ func showListLabel(entries []Entry) {
 		fmt.Println(entry.Label)
 	}
 }
-
-func showCompletions(entries []Entry) {
-	// Output only labels for completion
-	for _, entry := range entries {
-		fmt.Println(entry.Label)
-	}
-}
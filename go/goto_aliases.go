@@ -0,0 +1,114 @@
+// goto_aliases.go - Aliases and multi-key shortcuts
+// This file contains the aliases of destinations, further names that work
+// like the label on the command line, in label input mode and in completion:
+//
+//	[backend]
+//	path = "~/src/app/api"
+//	aliases = ["api", "server"]
+//	shortcut = "gw"
+//
+// Shortcuts may have several characters. In cursor mode the typed letters are
+// buffered while a longer shortcut still starts with them; the menu then
+// shows the pending prefix and narrows to the candidates until the shortcut
+// is complete, Enter is pressed or the digit timeout elapses.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// findEntryByAlias returns the entry with the alias, ignoring case
+func findEntryByAlias(entries []Entry, name string) (Entry, bool) {
+	for _, entry := range entries {
+		for _, alias := range entry.Aliases {
+			if strings.EqualFold(alias, name) {
+				return entry, true
+			}
+		}
+	}
+	return Entry{}, false
+}
+
+// aliasCompletions returns the aliases that do not shadow a label
+func aliasCompletions(entries []Entry) []completion {
+	var candidates []completion
+	for _, entry := range entries {
+		for _, alias := range entry.Aliases {
+			if !hasLabel(entries, alias) {
+				candidates = append(candidates, completion{alias, "→ " + entry.Label})
+			}
+		}
+	}
+	return candidates
+}
+
+// aliasProblems returns the aliases that are empty, shadowed by a label or
+// used by more than one destination
+func aliasProblems(entries []Entry) []string {
+	var problems []string
+	owners := make(map[string]string)
+	for _, entry := range entries {
+		for _, alias := range entry.Aliases {
+			key := strings.ToLower(alias)
+			switch {
+			case alias == "":
+				problems = append(problems, fmt.Sprintf(messages.CheckEmptyAlias, entry.Label))
+			case hasLabel(entries, alias):
+				problems = append(problems, fmt.Sprintf(messages.CheckAliasIsLabel, entry.Label, alias))
+			case owners[key] != "":
+				problems = append(problems, fmt.Sprintf(messages.CheckDuplicateAlias, entry.Label, alias, owners[key]))
+			default:
+				owners[key] = entry.Label
+			}
+		}
+	}
+	return problems
+}
+
+// shortcutMatches reports whether the typed letters are a shortcut and
+// whether a longer shortcut starts with them
+func shortcutMatches(shortcutMap map[string]int, typed string) (exact, longer bool) {
+	for shortcut := range shortcutMap {
+		if shortcut == typed {
+			exact = true
+		} else if strings.HasPrefix(shortcut, typed) {
+			longer = true
+		}
+	}
+	return exact, longer
+}
+
+// shortcutCandidates returns the shortcuts starting with the typed letters
+func shortcutCandidates(shortcutMap map[string]int, typed string) []string {
+	var candidates []string
+	for shortcut := range shortcutMap {
+		if strings.HasPrefix(shortcut, typed) {
+			candidates = append(candidates, shortcut)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// firstKey returns the first key of a shortcut, the one pressed to start it
+func firstKey(shortcut string) string {
+	r, size := utf8.DecodeRuneInString(shortcut)
+	if r == utf8.RuneError {
+		return ""
+	}
+	return shortcut[:size]
+}
+
+// aliasContains reports whether an alias of the entry contains the lower-case query
+func aliasContains(entry Entry, query string) bool {
+	for _, alias := range entry.Aliases {
+		if strings.Contains(strings.ToLower(alias), query) {
+			return true
+		}
+	}
+	return false
+}
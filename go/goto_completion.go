@@ -0,0 +1,347 @@
+// goto_completion.go - Shell completion
+// This file contains the --complete protocol used by the completion scripts
+// and the generator of the scripts for bash, zsh and fish ("goto completion").
+//
+// A word containing a slash completes the subdirectories beneath the
+// destination named by its first segment ("mono/serv" -> "mono/services/").
+//
+// Protocol: goto --complete [WORDS...] CURRENT prints one candidate per line
+// as "value<TAB>description". WORDS are the words typed after "goto" and
+// CURRENT is the word being completed (possibly empty). Without any words,
+// only the labels are printed, as in earlier versions.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// completionShells lists the shells supported by "goto completion"
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completion is a completion candidate
+type completion struct {
+	Value       string
+	Description string
+}
+
+// showCompletions prints the completion candidates for the command line
+func showCompletions(entries []Entry, appConfig AppConfig) {
+	if len(appConfig.CompleteWords) == 0 {
+		// Earlier protocol: labels only
+		for _, entry := range entries {
+			fmt.Println(entry.Label)
+		}
+		return
+	}
+
+	for _, c := range completionCandidates(entries, appConfig) {
+		if c.Description == "" {
+			fmt.Println(c.Value)
+		} else {
+			fmt.Printf("%s\t%s\n", c.Value, c.Description)
+		}
+	}
+}
+
+// completionCandidates returns the candidates for the word being completed
+func completionCandidates(entries []Entry, appConfig AppConfig) []completion {
+	words := appConfig.CompleteWords
+	current := words[len(words)-1]
+
+	// The value of an option
+	if len(words) >= 2 {
+		if option := findOption(words[len(words)-2]); option != nil && option.Value != valueNone {
+			switch option.Value {
+			case valueLang:
+				return valueCompletions(availableLanguages())
+			case valueProfile:
+				return valueCompletions(listProfiles())
+			case valueTag:
+				return tagCompletions(entries, current)
+			case valueSort:
+				return valueCompletions(sortModes)
+			}
+			return nil // Files are completed by the shell
+		}
+	}
+
+	// Arguments of commands
+	if args := appConfig.FilteredArgs; len(args) > 0 || appConfig.Pick {
+		switch {
+		case appConfig.Pick:
+			if strings.HasPrefix(current, "-") {
+				return append(optionCompletions(), completion{pickLabelOption, messages.PickDestination})
+			}
+		case args[0] == "--list":
+			return valueCompletions([]string{jsonOption})
+		case args[0] == "which":
+			if strings.HasPrefix(current, "-") {
+				return append(optionCompletions(), completion{jsonOption, messages.HelpWhichJSON})
+			}
+		case args[0] == "completion" && len(args) == 1:
+			return valueCompletions(completionShells)
+		case args[0] == profileCommand && len(args) == 1:
+			return valueCompletions(profileSubcommands)
+		case args[0] == profileCommand && len(args) == 2 && args[1] == "use":
+			return valueCompletions(listProfiles())
+		}
+		return nil
+	}
+
+	// The first argument: options and commands, or destinations
+	if strings.HasPrefix(current, "-") {
+		candidates := optionCompletions()
+		for _, command := range cliCommands {
+			for _, name := range command.Names {
+				if strings.HasPrefix(name, "-") {
+					candidates = append(candidates, completion{name, command.Desc()})
+				}
+			}
+		}
+		return candidates
+	}
+	if strings.Contains(current, "/") {
+		historyFile := appConfig.HistoryFile
+		if historyFile == "" {
+			historyFile, _ = getHistoryFilePath()
+		}
+		return subpathCompletions(entries, buildShortcutMap(entries), current, historyFile)
+	}
+	return destinationCompletions(entries, current)
+}
+
+// valueCompletions returns the values as candidates without descriptions
+func valueCompletions(values []string) []completion {
+	candidates := make([]completion, 0, len(values))
+	for _, value := range values {
+		candidates = append(candidates, completion{Value: value})
+	}
+	return candidates
+}
+
+// optionCompletions returns the options accepted anywhere on the command line
+func optionCompletions() []completion {
+	var candidates []completion
+	for _, option := range cliOptions {
+		candidates = append(candidates, completion{option.Name, option.Desc()})
+	}
+	return candidates
+}
+
+// destinationCompletions returns labels, commands and, when the word has been
+// started, matching shortcuts and numbers
+func destinationCompletions(entries []Entry, current string) []completion {
+	var candidates []completion
+	for _, entry := range entries {
+		description := expandPath(entry.Path)
+		if entry.Shortcut != "" {
+			description = "(" + entry.Shortcut + ") " + description
+		}
+		candidates = append(candidates, completion{entry.Label, description})
+	}
+	// Local destinations can be typed without their scope unless a label shadows it
+	for _, entry := range entries {
+		if entry.Scope == "" {
+			continue
+		}
+		name := strings.TrimPrefix(entry.Label, entry.Scope+":")
+		if local, _ := findLocalByName(entries, name); local.Label == entry.Label && !hasLabel(entries, name) {
+			candidates = append(candidates, completion{name, "→ " + entry.Label})
+		}
+	}
+
+	candidates = append(candidates, aliasCompletions(entries)...)
+
+	if current != "" {
+		for _, entry := range entries {
+			if entry.Shortcut != "" && strings.HasPrefix(entry.Shortcut, current) && entry.Shortcut != entry.Label {
+				candidates = append(candidates, completion{entry.Shortcut, "→ " + entry.Label})
+			}
+		}
+		if _, err := strconv.Atoi(current); err == nil {
+			for i, entry := range entries {
+				if number := entryNumber(entries, i); strings.HasPrefix(number, current) {
+					candidates = append(candidates, completion{number, "→ " + entry.Label})
+				}
+			}
+		}
+	}
+
+	for _, command := range cliCommands {
+		for _, name := range command.Names {
+			if !strings.HasPrefix(name, "-") {
+				candidates = append(candidates, completion{name, command.Desc()})
+			}
+		}
+	}
+	if appSettings.pluginPrecedence() != pluginsOff {
+		for _, name := range listPlugins() {
+			if !isBuiltinCommand(name) {
+				candidates = append(candidates, completion{name, messages.PluginCompletion})
+			}
+		}
+	}
+	return candidates
+}
+
+// hasLabel reports whether an entry has the label
+func hasLabel(entries []Entry, label string) bool {
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Label, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// printCompletionScript prints the completion script for the shell
+func printCompletionScript(args []string) {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	}
+
+	switch shell {
+	case "bash":
+		fmt.Print(bashCompletionScript())
+	case "zsh":
+		fmt.Print(zshCompletionScript())
+	case "fish":
+		fmt.Print(fishCompletionScript())
+	default:
+		fmt.Fprintf(os.Stderr, messages.ErrorUnknownShell+"\n", shell, strings.Join(completionShells, ", "))
+		os.Exit(1)
+	}
+}
+
+// completionScript fills in the option lists of a script template
+func completionScript(template string) string {
+	return strings.NewReplacer(
+		"{{FILE_OPTIONS}}", strings.Join(optionNames(valueFile), "|"),
+		"{{KEYS_OPTIONS}}", strings.Join(optionNames(valueKeys), "|"),
+	).Replace(template)
+}
+
+// bashCompletionScript returns the completion script for bash
+func bashCompletionScript() string {
+	return completionScript(`# bash completion for goto
+# Generated by "goto completion bash"
+
+_goto_completion() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    COMPREPLY=()
+
+    case "$prev" in
+        {{FILE_OPTIONS}})
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return 0
+            ;;
+        {{KEYS_OPTIONS}})
+            return 0
+            ;;
+    esac
+
+    local IFS=$'\n'
+    local line
+    local candidates=()
+    for line in $(goto --complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+        candidates+=("${line%%$'\t'*}")
+    done
+    COMPREPLY=($(compgen -W "${candidates[*]}" -- "$cur"))
+
+    # Keep completing beneath a directory, as cd does
+    if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == */ ]]; then
+        compopt -o nospace 2>/dev/null
+    fi
+    return 0
+}
+
+# Register the completion function
+complete -F _goto_completion goto
+`)
+}
+
+// zshCompletionScript returns the completion script for zsh
+func zshCompletionScript() string {
+	return completionScript(`#compdef goto
+# zsh completion for goto
+# Generated by "goto completion zsh"
+
+_goto() {
+    local -a candidates subdirs
+    local line value desc
+
+    case "${words[CURRENT-1]}" in
+        {{FILE_OPTIONS}})
+            _files
+            return
+            ;;
+        {{KEYS_OPTIONS}})
+            return
+            ;;
+    esac
+
+    for line in ${(f)"$(goto --complete "${(@)words[2,CURRENT]}" 2>/dev/null)"}; do
+        value="${line%%$'\t'*}"
+        desc=""
+        [[ "$line" == *$'\t'* ]] && desc="${line#*$'\t'}"
+        if [[ "$value" == */ ]]; then
+            subdirs+=("${value//:/\\:}${desc:+:$desc}")
+        else
+            candidates+=("${value//:/\\:}${desc:+:$desc}")
+        fi
+    done
+    _describe -t goto 'goto' candidates
+    # Keep completing beneath a directory, as cd does
+    _describe -t subdirs 'subdirectory' subdirs -S ''
+}
+
+if [[ "$funcstack[1]" == "_goto" ]]; then
+    _goto "$@"
+else
+    compdef _goto goto
+fi
+`)
+}
+
+// fishCompletionScript returns the completion script for fish
+func fishCompletionScript() string {
+	var options strings.Builder
+	for _, option := range cliOptions {
+		flag := "-l"
+		if !strings.HasPrefix(option.Name, "--") {
+			flag = "-s"
+		}
+		fmt.Fprintf(&options, "complete -c goto %s %s", flag, strings.TrimLeft(option.Name, "-"))
+		switch option.Value {
+		case valueFile:
+			options.WriteString(" -r -F")
+		case valueLang, valueKeys, valueProfile, valueTag, valueSort:
+			options.WriteString(" -x")
+		}
+		fmt.Fprintf(&options, " -d %s\n", fishQuote(option.Desc()))
+	}
+	return strings.Replace(`# fish completion for goto
+# Generated by "goto completion fish"
+
+function __goto_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l current (commandline -ct)
+    goto --complete $tokens "$current" 2>/dev/null
+end
+
+complete -c goto -f
+{{OPTIONS}}complete -c goto -a '(__goto_complete)'
+`, "{{OPTIONS}}", options.String(), 1)
+}
+
+// fishQuote quotes a string for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -11,23 +11,32 @@ import (
 	"os/user"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
-// getHistoryFilePath returns the full path to the history file
+// getHistoryFilePath returns the full path to the history file of the active
+// profile, for callers that were not given one
 func getHistoryFilePath() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
+	if historyFile := os.Getenv(historyEnvVar); historyFile != "" {
+		return historyFile, nil
+	}
+	if _, err := user.Current(); err != nil {
 		return "", err
 	}
-	return filepath.Join(usr.HomeDir, ".goto.history.json"), nil
+	return profileHistoryPath(activeProfile), nil
 }
 
 // createDefaultConfig creates a default configuration file
 func createDefaultConfig(tomlFile string) {
-	err := os.WriteFile(tomlFile, []byte(DefaultConfig), 0644)
+	// The sample entry that edits the configuration opens this file
+	content := strings.ReplaceAll(DefaultConfig, "~/.goto.toml", displayPath(tomlFile))
+	err := ensureParentDir(tomlFile)
+	if err == nil {
+		err = os.WriteFile(tomlFile, []byte(content), 0644)
+	}
 	if err != nil {
 		fmt.Printf("%s %v\n", messages.ErrorWritingConfigFile, err)
 		os.Exit(1)
@@ -37,12 +46,189 @@ func createDefaultConfig(tomlFile string) {
 
 // loadConfig loads the TOML configuration file
 func loadConfig(tomlFile string) (map[string]Destination, error) {
-	var config map[string]Destination
-	_, err := toml.DecodeFile(tomlFile, &config)
+	config, _, err := loadConfigWithSettings(tomlFile)
+	return config, err
+}
+
+// loadConfigWithSettings loads destinations and settings sections from the TOML configuration file
+func loadConfigWithSettings(tomlFile string) (map[string]Destination, Settings, error) {
+	return loadConfigSource(tomlFile, false)
+}
+
+// loadConfigSource loads a configuration file and expands its generator and
+// provider entries. Providers of local files only run once trusted.
+func loadConfigSource(tomlFile string, local bool) (map[string]Destination, Settings, error) {
+	config, settings, err := decodeConfigFile(tomlFile)
 	if err != nil {
-		return nil, err
+		return nil, settings, err
+	}
+
+	// The settings of this file are not active yet, so its cache TTL is passed on
+	ttl := appSettings.scanCacheTTL()
+	if settings.General.ScanCacheTTL != "" {
+		ttl = settings.General.scanCacheTTL()
+	}
+	if err := expandGenerators(config, filepath.Dir(tomlFile), ttl); err != nil {
+		return nil, settings, err
+	}
+	if err := expandProviders(config, tomlFile, local); err != nil {
+		return nil, settings, err
+	}
+	return config, settings, nil
+}
+
+// decodeConfigFile decodes destinations and settings sections without
+// expanding generator and provider entries
+func decodeConfigFile(tomlFile string) (map[string]Destination, Settings, error) {
+	var settings Settings
+	var raw map[string]toml.Primitive
+	md, err := toml.DecodeFile(tomlFile, &raw)
+	if err != nil {
+		return nil, settings, err
+	}
+
+	// The decoded map loses the order of the tables, which the config order needs
+	order := make(map[string]int)
+	for _, key := range md.Keys() {
+		if _, seen := order[key[0]]; len(key) == 1 && !seen {
+			order[key[0]] = len(order)
+		}
+	}
+
+	config := make(map[string]Destination)
+	for name, prim := range raw {
+		// A settings section that defines a path is an ordinary destination
+		if settingsSections[name] && !md.IsDefined(name, "path") {
+			if err := decodeSettingsSection(md, name, prim, &settings); err != nil {
+				return nil, settings, fmt.Errorf("[%s] %w", name, err)
+			}
+			continue
+		}
+
+		var dest Destination
+		if err := md.PrimitiveDecode(prim, &dest); err != nil {
+			return nil, settings, fmt.Errorf("[%s] %w", name, err)
+		}
+		dest.Order = order[name]
+		config[name] = dest
+	}
+	return config, settings, nil
+}
+
+// decodeSettingsSection decodes a settings section into settings
+func decodeSettingsSection(md toml.MetaData, name string, prim toml.Primitive, settings *Settings) error {
+	switch name {
+	case "keys":
+		var raw map[string]interface{}
+		if err := md.PrimitiveDecode(prim, &raw); err != nil {
+			return err
+		}
+		settings.Keys = make(map[string][]string)
+		for action, value := range raw {
+			keys, err := toStringList(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", action, err)
+			}
+			settings.Keys[action] = keys
+		}
+	case "theme":
+		return md.PrimitiveDecode(prim, &settings.Theme)
+	case "settings":
+		return md.PrimitiveDecode(prim, &settings.General)
+	}
+	return nil
+}
+
+// applySettings activates the settings sections loaded from the configuration file
+func applySettings(settings Settings) error {
+	var err error
+	if keyBindings, err = newKeyBindings(settings.Keys); err != nil {
+		return fmt.Errorf("[keys] %w", err)
+	}
+	if theme, err = newTheme(settings.Theme); err != nil {
+		return fmt.Errorf("[theme] %w", err)
+	}
+
+	general := settings.General
+	defaults := defaultGeneralSettings()
+	if len(general.Columns) == 0 {
+		general.Columns = defaults.Columns
+	}
+	if len(general.HistoryColumns) == 0 {
+		general.HistoryColumns = defaults.HistoryColumns
+	}
+	if general.LabelWidth <= 0 {
+		general.LabelWidth = defaults.LabelWidth
+	}
+	if err := validateColumns(general.Columns); err != nil {
+		return fmt.Errorf("[settings] columns: %w", err)
+	}
+	if err := validateColumns(general.HistoryColumns); err != nil {
+		return fmt.Errorf("[settings] history_columns: %w", err)
+	}
+	if general.StatusTimeout != "" {
+		if _, err := time.ParseDuration(general.StatusTimeout); err != nil {
+			return fmt.Errorf("[settings] status_timeout: %w", err)
+		}
+	}
+	if general.DigitTimeout != "" {
+		if _, err := time.ParseDuration(general.DigitTimeout); err != nil {
+			return fmt.Errorf("[settings] digit_timeout: %w", err)
+		}
+	}
+	if general.ScanCacheTTL != "" {
+		if _, err := time.ParseDuration(general.ScanCacheTTL); err != nil {
+			return fmt.Errorf("[settings] scan_cache_ttl: %w", err)
+		}
+	}
+	if general.PluginPrecedence != "" && !validPluginPrecedence(general.PluginPrecedence) {
+		return fmt.Errorf("[settings] plugin_precedence: unknown value %q (available: %s)", general.PluginPrecedence, strings.Join(pluginPrecedences, ", "))
+	}
+	if general.Sort != "" && !validSortMode(general.Sort) {
+		return fmt.Errorf("[settings] sort: unknown value %q (available: %s)", general.Sort, strings.Join(sortModes, ", "))
+	}
+	if general.NestedShell != "" && !validNestedPolicy(general.NestedShell) {
+		return fmt.Errorf("[settings] nested_shell: unknown policy %q (available: %s)", general.NestedShell, strings.Join(nestedPolicies, ", "))
+	}
+	if general.Language != "" {
+		lang, ok := resolveLanguage(general.Language)
+		if !ok {
+			return fmt.Errorf("[settings] language: unknown language %q (available: %s)", general.Language, strings.Join(availableLanguages(), ", "))
+		}
+		if !languageFixed {
+			setLanguage(lang)
+		}
+	}
+	appSettings = general
+	return nil
+}
+
+// defaultGeneralSettings returns the [settings] values used when they are not configured
+func defaultGeneralSettings() GeneralSettings {
+	return GeneralSettings{
+		Columns:        defaultColumns,
+		HistoryColumns: defaultHistoryColumns,
+		LabelWidth:     defaultLabelWidth,
 	}
-	return config, nil
+}
+
+// toStringList converts a TOML string or array of strings to a string slice
+func toStringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string but got %v", item)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("expected a string or an array of strings but got %v", value)
 }
 
 // loadHistory loads the JSON history file
@@ -103,95 +289,48 @@ func saveHistory(historyFile string, history History) error {
 		return err
 	}
 
+	if err := ensureParentDir(historyFile); err != nil {
+		return err
+	}
 	return os.WriteFile(historyFile, data, 0644)
 }
 
-// getEntriesFromConfig converts config map to sorted Entry slice
+// getEntriesFromConfig converts config map to an Entry slice in the order of
+// the sort setting
 func getEntriesFromConfig(config map[string]Destination, customHistoryFile string) []Entry {
-	var entries []Entry
-
-	// Load history from separate JSON file for sorting
-	var historyFile string
-	var err error
-
-	if customHistoryFile != "" {
-		historyFile = customHistoryFile
-	} else {
-		historyFile, err = getHistoryFilePath()
-		if err != nil {
-			// If we can't get history file path, proceed without history sorting
-			for label, dest := range config {
-				entries = append(entries, Entry{
-					Label:    label,
-					Path:     dest.Path,
-					Shortcut: dest.Shortcut,
-					Command:  dest.Command,
-				})
-			}
-			// Sort alphabetically if no history available
-			sort.Slice(entries, func(i, j int) bool {
-				return entries[i].Label < entries[j].Label
-			})
-			return entries
-		}
+	// Without a history file (or with a damaged one) entries have no history
+	historyFile := customHistoryFile
+	if historyFile == "" {
+		historyFile, _ = getHistoryFilePath()
 	}
-
-	history, err := loadHistory(historyFile)
-	if err != nil {
-		// If history file doesn't exist or has error, proceed without history sorting
-		for label, dest := range config {
-			entries = append(entries, Entry{
-				Label:    label,
-				Path:     dest.Path,
-				Shortcut: dest.Shortcut,
-				Command:  dest.Command,
-			})
+	var history History
+	historyMap := make(map[string]HistoryEntry)
+	historyLoaded := false
+	if historyFile != "" {
+		if loaded, err := loadHistory(historyFile); err == nil {
+			history, historyLoaded = loaded, true
 		}
-		// Sort alphabetically if no history available
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].Label < entries[j].Label
-		})
-		return entries
 	}
-
-	// Create a map for quick lookup of history
-	historyMap := make(map[string]time.Time)
 	for _, hist := range history.Entries {
-		historyMap[hist.Label] = hist.LastUsed
+		historyMap[hist.Label] = hist
 	}
 
-	// Collect all entries
+	entries := make([]Entry, 0, len(config))
 	for label, dest := range config {
-		entries = append(entries, Entry{
-			Label:    label,
-			Path:     dest.Path,
-			Shortcut: dest.Shortcut,
-			Command:  dest.Command,
-		})
+		entry := newEntry(label, dest)
+		entry.LastUsed = historyMap[label].LastUsed
+		entry.Visits = historyMap[label].Visits
+		entries = append(entries, entry)
 	}
+	sortEntries(entries, appSettings.sortMode())
 
-	// Sort entries by history (most recent first)
-	sort.Slice(entries, func(i, j int) bool {
-		timeI, hasI := historyMap[entries[i].Label]
-		timeJ, hasJ := historyMap[entries[j].Label]
-
-		// If both have history, sort by time (most recent first)
-		if hasI && hasJ {
-			return timeI.After(timeJ)
+	// Newly allocated stable IDs are stored with the history, unless it could
+	// not be read: saving would replace the user's damaged file
+	if appSettings.StableIDs && assignStableIDs(entries, &history) && historyLoaded {
+		if err := saveHistory(historyFile, history); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningFailedToUpdateHistory, err)
 		}
-
-		// If only one has history, prioritize it
-		if hasI && !hasJ {
-			return true
-		}
-		if !hasI && hasJ {
-			return false
-		}
-
-		// If neither has history, sort alphabetically
-		return entries[i].Label < entries[j].Label
-	})
-
+	}
 	return entries
 }
 
@@ -0,0 +1,297 @@
+// goto_generator.go - Generator entries
+// This file contains entries that expand into one destination per matching
+// directory when the configuration is loaded:
+//
+//	[projects]
+//	glob = "~/src/*"
+//
+//	[work]
+//	scan = { root = "~/work", depth = 2, marker = ".git" }
+//	label = "{parent}-{name}"
+//
+// The label template may use {name} (the directory name), {parent} (the name
+// of its parent directory), {rel} (the path below the glob or scan root) and
+// {section} (the name of the generator table); it defaults to "{name}".
+// command, description and tags apply to every generated destination.
+// Labels defined elsewhere in the file win over generated ones.
+//
+// Expansions are cached in the cache directory, so completion does not scan
+// on every key press. Completion uses the cache of any age; otherwise it is
+// reused for scan_cache_ttl unless the root directory changed.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultScanCacheTTL = 5 * time.Minute
+
+// ScanSpec describes the directories found by a scan generator
+type ScanSpec struct {
+	Root   string `toml:"root"   json:"root"`
+	Depth  int    `toml:"depth"  json:"depth"`  // Levels below root to search (default: 1)
+	Marker string `toml:"marker" json:"marker"` // File or directory a match must contain, e.g. ".git"
+}
+
+// generatorCache represents the cache file: generator key -> expansion
+type generatorCache struct {
+	Entries map[string]cachedExpansion `json:"entries"`
+}
+
+// cachedExpansion is the directories found by a generator at a time
+type cachedExpansion struct {
+	Time     time.Time `json:"time"`
+	RootTime time.Time `json:"root_time"` // Modification time of the root when it was expanded
+	Paths    []string  `json:"paths"`
+}
+
+// preferCachedExpansions makes generators and providers use a cached
+// expansion of any age, so completion stays fast; it is set for --complete
+var preferCachedExpansions bool
+
+// cachedExpansionsOnly makes generators and providers return their cached
+// expansion, or nothing, without scanning or running a command; it is set for
+// "goto which", which prompts and scripts call on every directory change
+var cachedExpansionsOnly bool
+
+// isGenerator reports whether a destination is a generator entry
+func (d Destination) isGenerator() bool {
+	return d.Glob != "" || d.Scan != nil
+}
+
+// scanCacheTTL returns how long expansions are reused
+func (g GeneralSettings) scanCacheTTL() time.Duration {
+	if d, err := time.ParseDuration(g.ScanCacheTTL); err == nil {
+		return d
+	}
+	return defaultScanCacheTTL
+}
+
+// validateGenerator checks the fields of a generator entry
+func validateGenerator(dest Destination) error {
+	switch {
+	case dest.Glob != "" && dest.Scan != nil:
+		return fmt.Errorf("glob and scan cannot be combined")
+	case dest.Path != "" || dest.Provider != "":
+		return fmt.Errorf("path and provider cannot be combined with glob or scan")
+	case dest.Shortcut != "":
+		return fmt.Errorf("generated destinations cannot share a shortcut")
+	case dest.Scan != nil && dest.Scan.Root == "":
+		return fmt.Errorf("scan: root is required")
+	case dest.Scan != nil && dest.Scan.Depth < 0:
+		return fmt.Errorf("scan: depth must not be negative")
+	}
+	return nil
+}
+
+// expandGenerators replaces the generator entries of the configuration with
+// the destinations they generate. Relative roots are resolved against base.
+func expandGenerators(config map[string]Destination, base string, ttl time.Duration) error {
+	names := make([]string, 0)
+	for name, dest := range config {
+		if dest.isGenerator() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	generated := make(map[string]Destination)
+	for _, name := range names {
+		generator := config[name]
+		delete(config, name)
+		if err := validateGenerator(generator); err != nil {
+			return fmt.Errorf("[%s] %w", name, err)
+		}
+
+		root, paths := generatorPaths(generator, base, ttl)
+		for _, path := range paths {
+			label := generatedLabel(generator.Label, name, root, path)
+			if _, exists := generated[label]; exists {
+				continue // The first directory in path order wins
+			}
+			generated[label] = Destination{
+				Path:        path,
+				Command:     generator.Command,
+				Description: generator.Description,
+				Tags:        generator.Tags,
+				Pinned:      generator.Pinned,
+				Order:       generator.Order,
+			}
+		}
+	}
+
+	for label, dest := range generated {
+		if _, exists := config[label]; !exists {
+			config[label] = dest
+		}
+	}
+	return nil
+}
+
+// generatedLabel fills in the label template for a directory
+func generatedLabel(template, section, root, path string) string {
+	if template == "" {
+		template = "{name}"
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	return strings.NewReplacer(
+		"{name}", filepath.Base(path),
+		"{parent}", filepath.Base(filepath.Dir(path)),
+		"{rel}", filepath.ToSlash(rel),
+		"{section}", section,
+	).Replace(template)
+}
+
+// generatorPaths returns the root and the directories of a generator, from
+// the cache when it is younger than ttl
+func generatorPaths(generator Destination, base string, ttl time.Duration) (string, []string) {
+	pattern := generator.Glob
+	if generator.Scan != nil {
+		pattern = generator.Scan.Root
+	}
+	pattern = expandPath(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(base, pattern)
+	}
+
+	root := pattern
+	if generator.Glob != "" {
+		root = globRoot(pattern)
+	}
+
+	key := "glob:" + pattern
+	if generator.Scan != nil {
+		key = fmt.Sprintf("scan:%s:%d:%s", pattern, generator.Scan.Depth, generator.Scan.Marker)
+	}
+
+	cache := loadGeneratorCache()
+	if cached, ok := cache.Entries[key]; cachedExpansionsOnly || ok && (preferCachedExpansions || cacheFresh(cached, root, ttl)) {
+		return root, cached.Paths
+	}
+
+	modified := rootTime(root)
+	var paths []string
+	if generator.Glob != "" {
+		paths = globDirectories(pattern)
+	} else {
+		paths = scanDirectories(pattern, generator.Scan.Depth, generator.Scan.Marker)
+	}
+	cache.Entries[key] = cachedExpansion{Time: time.Now(), RootTime: modified, Paths: paths}
+	saveGeneratorCache(cache)
+	return root, paths
+}
+
+// cacheFresh reports whether an expansion can be reused: it is younger than
+// ttl and no directory was added to or removed from root since
+func cacheFresh(cached cachedExpansion, root string, ttl time.Duration) bool {
+	return time.Since(cached.Time) < ttl && rootTime(root).Equal(cached.RootTime)
+}
+
+// rootTime returns the modification time of a root, zero when it is missing
+func rootTime(root string) time.Time {
+	if info, err := os.Stat(root); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// globRoot returns the directory of a glob pattern before its first wildcard
+func globRoot(pattern string) string {
+	root := pattern
+	for strings.ContainsAny(root, "*?[") {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// globDirectories returns the directories matching a glob pattern. As in the
+// shell, hidden directories only match a pattern starting with a dot.
+func globDirectories(pattern string) []string {
+	matches, _ := filepath.Glob(pattern)
+	hidden := strings.HasPrefix(filepath.Base(pattern), ".")
+	var dirs []string
+	for _, match := range matches {
+		if strings.HasPrefix(filepath.Base(match), ".") && !hidden {
+			continue
+		}
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			dirs = append(dirs, match)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// scanDirectories returns the directories up to depth levels below root. With
+// a marker, only directories containing it match and they are not descended
+// into; without one, the directories at exactly depth match. Hidden
+// directories are skipped.
+func scanDirectories(root string, depth int, marker string) []string {
+	if depth == 0 {
+		depth = 1
+	}
+	var dirs []string
+	var walk func(dir string, level int)
+	walk = func(dir string, level int) {
+		children, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, child := range children {
+			if !child.IsDir() || strings.HasPrefix(child.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, child.Name())
+			if marker != "" {
+				if _, err := os.Stat(filepath.Join(path, marker)); err == nil {
+					dirs = append(dirs, path)
+					continue
+				}
+			} else if level == depth {
+				dirs = append(dirs, path)
+			}
+			if level < depth {
+				walk(path, level+1)
+			}
+		}
+	}
+	walk(root, 1)
+	sort.Strings(dirs)
+	return dirs
+}
+
+// generatorCachePath returns the cache file of the expansions
+func generatorCachePath() string {
+	return filepath.Join(cacheDir(), "generators.json")
+}
+
+// loadGeneratorCache loads the cache; a missing or damaged file is empty
+func loadGeneratorCache() generatorCache {
+	cache := generatorCache{Entries: make(map[string]cachedExpansion)}
+	if data, err := os.ReadFile(generatorCachePath()); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]cachedExpansion)
+	}
+	return cache
+}
+
+// saveGeneratorCache saves the cache; failures only cost a rescan
+func saveGeneratorCache(cache generatorCache) {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil || ensureParentDir(generatorCachePath()) != nil {
+		return
+	}
+	os.WriteFile(generatorCachePath(), data, 0644)
+}
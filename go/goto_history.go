@@ -6,26 +6,20 @@ package main
 
 import (
 	"fmt"
-	"os/user"
-	"path/filepath"
+	"os"
 	"sort"
 	"strings"
 	"time"
+
+	"golang.org/x/term"
 )
 
 // ShowHistory displays the usage history with timestamps and paths
 func ShowHistory(customConfigFile, customHistoryFile string) {
 	// Get configuration file path
-	var tomlFile string
-	if customConfigFile != "" {
-		tomlFile = customConfigFile
-	} else {
-		usr, err := user.Current()
-		if err != nil {
-			fmt.Printf("%s %v\n", messages.ErrorGettingUser, err)
-			return
-		}
-		tomlFile = filepath.Join(usr.HomeDir, ".goto.toml")
+	tomlFile := customConfigFile
+	if tomlFile == "" {
+		tomlFile = profileConfigPath(activeProfile)
 	}
 
 	// Load configuration
@@ -34,6 +28,7 @@ func ShowHistory(customConfigFile, customHistoryFile string) {
 		fmt.Printf("%s %v\n", messages.ErrorReadingConfig, err)
 		return
 	}
+	mergeLocalDestinations(config, tomlFile)
 
 	// Get history file path
 	var historyFile string
@@ -70,26 +65,36 @@ func ShowHistory(customConfigFile, customHistoryFile string) {
 		return sortedHistory[i].LastUsed.After(sortedHistory[j].LastUsed)
 	})
 
-	for i, hist := range sortedHistory {
-		// Format timestamp for display
-		timeStr := hist.LastUsed.Format("2006-01-02 15:04:05")
-
-		// Get destination path if exists
-		pathStr := ""
+	entries := make([]Entry, 0, len(sortedHistory))
+	for _, hist := range sortedHistory {
+		// Get destination details if the label still exists
+		entry := Entry{Label: hist.Label}
 		if dest, exists := config[hist.Label]; exists {
-			pathStr = fmt.Sprintf(" → %s", expandPath(dest.Path))
+			entry = newEntry(hist.Label, dest)
 		}
+		entry.LastUsed = hist.LastUsed
+		entries = append(entries, entry)
+	}
+	annotateOutputStatuses(entries)
 
-		fmt.Printf("%2d. %s%s\n", i+1, hist.Label, pathStr)
-		fmt.Printf("    📅 %s\n", timeStr)
+	rows := make([]tableRow, 0, len(entries))
+	for i, entry := range entries {
+		rows = append(rows, entryRow(entry, fmt.Sprintf("%d.", i+1)))
+	}
 
-		if i < len(sortedHistory)-1 {
-			fmt.Println()
-		}
+	layout := newTableLayout(appSettings.HistoryColumns, term.IsTerminal(int(os.Stdout.Fd())))
+	for _, line := range layout.render(rows) {
+		fmt.Println(line)
 	}
 }
 
 func UpdateHistory(tomlFile string, label string, customHistoryFile string) error {
+	return UpdateSubpathHistory(tomlFile, label, "", customHistoryFile)
+}
+
+// UpdateSubpathHistory credits the label with a visit. When subpath_counts is
+// enabled, a visit beneath the destination also counts the subpath.
+func UpdateSubpathHistory(tomlFile string, label string, subpath string, customHistoryFile string) error {
 	// Get history file path
 	var historyFile string
 	var err error
@@ -117,6 +122,7 @@ func UpdateHistory(tomlFile string, label string, customHistoryFile string) erro
 	for i, hist := range history.Entries {
 		if hist.Label == label {
 			history.Entries[i].LastUsed = now
+			history.Entries[i].Visits++
 			found = true
 			break
 		}
@@ -126,9 +132,22 @@ func UpdateHistory(tomlFile string, label string, customHistoryFile string) erro
 		history.Entries = append(history.Entries, HistoryEntry{
 			Label:    label,
 			LastUsed: now,
+			Visits:   1,
 		})
 	}
 
+	if subpath != "" && appSettings.SubpathCounts {
+		for i, hist := range history.Entries {
+			if hist.Label == label {
+				if hist.Subpaths == nil {
+					history.Entries[i].Subpaths = make(map[string]int)
+				}
+				history.Entries[i].Subpaths[subpath]++
+				break
+			}
+		}
+	}
+
 	// Save updated history
 	return saveHistory(historyFile, history)
 }
@@ -0,0 +1,158 @@
+// goto_ids.go - Stable destination numbers
+// This file contains the opt-in stable numbering (stable_ids = true). By
+// default the number of a destination is its position in the menu, which
+// changes as the history changes, so "goto 2" may open another destination
+// an hour later. With stable IDs every destination keeps its number: the one
+// given with id = N in the configuration, or one allocated on first sight and
+// stored in the history file. The menu keeps its order but shows and accepts
+// these numbers. Numbers of removed destinations are not reused: they stay in
+// the history file, as destinations of .goto.toml files and providers come
+// and go with the current directory and must get their numbers back.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validateIDs checks the id fields of the configuration when stable IDs are
+// enabled: they must be positive and unique
+func validateIDs(config map[string]Destination) error {
+	if !appSettings.StableIDs {
+		return nil
+	}
+	labels := make([]string, 0, len(config))
+	for label := range config {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	owners := make(map[int]string)
+	for _, label := range labels {
+		id := config[label].ID
+		switch {
+		case id < 0:
+			return fmt.Errorf("[%s] id: must be positive", label)
+		case id == 0:
+			continue
+		}
+		if owner, exists := owners[id]; exists {
+			return fmt.Errorf("[%s] id: %d is also used by [%s]", label, id, owner)
+		}
+		owners[id] = label
+	}
+	return nil
+}
+
+// assignStableIDs gives every entry its stable ID: the configured one, the
+// stored one unless another entry took it, or the smallest number that no
+// destination has ever had. It reports whether the stored IDs changed.
+func assignStableIDs(entries []Entry, history *History) bool {
+	if history.IDs == nil {
+		history.IDs = make(map[string]int)
+	}
+	changed := false
+	used := make(map[int]bool)  // IDs of the current entries
+	taken := make(map[int]bool) // IDs ever stored, including removed destinations
+	for _, id := range history.IDs {
+		taken[id] = true
+	}
+	for _, entry := range entries {
+		if entry.ID == 0 {
+			continue
+		}
+		used[entry.ID], taken[entry.ID] = true, true
+		if history.IDs[entry.Label] != entry.ID {
+			history.IDs[entry.Label] = entry.ID
+			changed = true
+		}
+	}
+
+	// New entries are numbered in label order, so the result does not depend on the history
+	order := make([]int, 0, len(entries))
+	for i := range entries {
+		if entries[i].ID == 0 {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return entries[order[a]].Label < entries[order[b]].Label
+	})
+	next := 1
+	for _, i := range order {
+		if id, ok := history.IDs[entries[i].Label]; ok && !used[id] {
+			entries[i].ID = id
+		} else {
+			for taken[next] {
+				next++
+			}
+			entries[i].ID = next
+			taken[next] = true
+			history.IDs[entries[i].Label] = next
+			changed = true
+		}
+		used[entries[i].ID] = true
+	}
+	return changed
+}
+
+// stableIDsEnabled reports whether the entries carry stable IDs
+func stableIDsEnabled(entries []Entry) bool {
+	return appSettings.StableIDs && len(entries) > 0 && entries[0].ID > 0
+}
+
+// entryNumber returns the number shown for and accepted for the entry at index
+func entryNumber(entries []Entry, index int) string {
+	if stableIDsEnabled(entries) {
+		return strconv.Itoa(entries[index].ID)
+	}
+	return strconv.Itoa(index + 1)
+}
+
+// findEntryByNumber returns the index of the entry with the number, or -1
+func findEntryByNumber(entries []Entry, number string) int {
+	if stableIDsEnabled(entries) {
+		id, err := strconv.Atoi(number)
+		for i, entry := range entries {
+			if err == nil && entry.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	num, err := strconv.Atoi(number)
+	if err != nil || num < 1 || num > len(entries) {
+		return -1
+	}
+	return num - 1
+}
+
+// numberPrefixes reports whether the digits are the number of an entry and
+// whether they start a longer number
+func numberPrefixes(entries []Entry, digits string) (exact, longer bool) {
+	for i := range entries {
+		number := entryNumber(entries, i)
+		if number == digits {
+			exact = true
+		} else if strings.HasPrefix(number, digits) {
+			longer = true
+		}
+	}
+	return exact, longer
+}
+
+// warnPositionalCommand warns that a positional number runs a command,
+// although the same number may name another destination after the next visit
+func warnPositionalCommand(arg string, entries []Entry, command, label string) {
+	if command == "" || stableIDsEnabled(entries) {
+		return
+	}
+	if _, err := strconv.Atoi(arg); err != nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, messages.WarningPositionalCommand+"\n", arg, label)
+}
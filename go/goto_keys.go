@@ -0,0 +1,378 @@
+// goto_keys.go - Key handling for the interactive menu
+// This file contains functions for reading key presses from the terminal,
+// normalizing key names, and mapping keys to menu actions configured
+// in the [keys] section of the configuration file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Menu actions that can be remapped in the [keys] section
+const (
+	ActionUp         = "up"
+	ActionDown       = "down"
+	ActionSelect     = "select"
+	ActionAdd        = "add"
+	ActionHelp       = "help"
+	ActionExit       = "exit"
+	ActionSearch     = "search"
+	ActionTag        = "tag"
+	ActionSwitchMode = "switch-mode"
+)
+
+// menuActions lists all menu actions in the order they are shown in help
+var menuActions = []string{
+	ActionUp, ActionDown, ActionSelect, ActionAdd,
+	ActionHelp, ActionExit, ActionSearch, ActionTag, ActionSwitchMode,
+}
+
+// defaultKeyBindings contains the built-in key bindings for each action
+var defaultKeyBindings = map[string][]string{
+	ActionUp:         {"up", "k"},
+	ActionDown:       {"down", "j"},
+	ActionSelect:     {"enter"},
+	ActionAdd:        {"+"},
+	ActionHelp:       {"?"},
+	ActionExit:       {"0"},
+	ActionSearch:     {"/"},
+	ActionTag:        {"tab"},
+	ActionSwitchMode: {"esc"},
+}
+
+// namedKeys maps accepted key names (and their aliases) to canonical names
+var namedKeys = map[string]string{
+	"up":        "up",
+	"down":      "down",
+	"left":      "left",
+	"right":     "right",
+	"enter":     "enter",
+	"return":    "enter",
+	"esc":       "esc",
+	"escape":    "esc",
+	"tab":       "tab",
+	"space":     "space",
+	"backspace": "backspace",
+	"home":      "home",
+	"end":       "end",
+	"pageup":    "pageup",
+	"pagedown":  "pagedown",
+}
+
+// keyDisplayNames contains short labels for named keys shown in hints
+var keyDisplayNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"enter":     "Enter",
+	"esc":       "ESC",
+	"tab":       "Tab",
+	"space":     "Space",
+	"backspace": "BS",
+	"home":      "Home",
+	"end":       "End",
+	"pageup":    "PgUp",
+	"pagedown":  "PgDn",
+}
+
+// KeyBindings maps key names to menu actions
+type KeyBindings struct {
+	byAction map[string][]string
+	byKey    map[string]string
+}
+
+// keyBindings holds the active key bindings for the interactive menu
+var keyBindings = mustDefaultKeyBindings()
+
+// mustDefaultKeyBindings builds the key bindings without user overrides
+func mustDefaultKeyBindings() KeyBindings {
+	kb, err := newKeyBindings(nil)
+	if err != nil {
+		panic(err)
+	}
+	return kb
+}
+
+// newKeyBindings builds key bindings from the defaults and the [keys] overrides.
+// An action listed in overrides replaces all of its default keys.
+func newKeyBindings(overrides map[string][]string) (KeyBindings, error) {
+	kb := KeyBindings{
+		byAction: make(map[string][]string),
+		byKey:    make(map[string]string),
+	}
+
+	for action := range overrides {
+		if _, ok := defaultKeyBindings[action]; !ok {
+			return kb, fmt.Errorf("unknown action %q (available: %s)", action, strings.Join(menuActions, ", "))
+		}
+	}
+
+	for _, action := range menuActions {
+		keys, ok := overrides[action]
+		if !ok {
+			keys = defaultKeyBindings[action]
+		}
+		for _, key := range keys {
+			name, err := normalizeKeyName(key)
+			if err != nil {
+				return kb, fmt.Errorf("%s: %w", action, err)
+			}
+			if other, exists := kb.byKey[name]; exists && other != action {
+				return kb, fmt.Errorf("key %q is bound to both %q and %q", name, other, action)
+			}
+			kb.byKey[name] = action
+			kb.byAction[action] = append(kb.byAction[action], name)
+		}
+	}
+
+	return kb, nil
+}
+
+// normalizeKeyName converts a key name from the configuration to its canonical form
+func normalizeKeyName(key string) (string, error) {
+	if utf8.RuneCountInString(key) == 1 {
+		if key == " " {
+			return "space", nil
+		}
+		return key, nil
+	}
+
+	lower := strings.ToLower(key)
+	if name, ok := namedKeys[lower]; ok {
+		return name, nil
+	}
+	if strings.HasPrefix(lower, "ctrl-") && len(lower) == 6 && lower[5] >= 'a' && lower[5] <= 'z' {
+		return lower, nil
+	}
+
+	return "", fmt.Errorf("invalid key name %q", key)
+}
+
+// action returns the menu action bound to the key, or "" if the key is unbound
+func (kb KeyBindings) action(key string) string {
+	return kb.byKey[key]
+}
+
+// label returns a short description of the keys bound to the action (e.g. "↑/k")
+func (kb KeyBindings) label(action string) string {
+	var names []string
+	for _, key := range kb.byAction[action] {
+		names = append(names, keyDisplayName(key))
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, "/")
+}
+
+// keyDisplayName returns the label of a single key shown in hints
+func keyDisplayName(key string) string {
+	if name, ok := keyDisplayNames[key]; ok {
+		return name
+	}
+	if strings.HasPrefix(key, "ctrl-") {
+		return "Ctrl-" + strings.ToUpper(key[5:])
+	}
+	return key
+}
+
+// validateKeyBindings returns warnings for bound keys that hide configured
+// shortcuts; a bound key also hides the shortcuts starting with it
+func validateKeyBindings(kb KeyBindings, entries []Entry) []string {
+	var warnings []string
+	for _, entry := range entries {
+		if entry.Shortcut == "" {
+			continue
+		}
+		key := firstKey(entry.Shortcut)
+		if action := kb.action(key); action != "" {
+			warnings = append(warnings, fmt.Sprintf(messages.WarningKeyShadowsShortcut, key, action, entry.Label))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// decodeKey converts raw bytes read from a terminal in raw mode to a key name
+func decodeKey(buf []byte) string {
+	if len(buf) == 0 {
+		return ""
+	}
+
+	// Escape sequences for arrow and navigation keys
+	if buf[0] == 27 {
+		if len(buf) == 1 {
+			return "esc"
+		}
+		if len(buf) >= 3 && (buf[1] == '[' || buf[1] == 'O') {
+			switch buf[2] {
+			case 'A':
+				return "up"
+			case 'B':
+				return "down"
+			case 'C':
+				return "right"
+			case 'D':
+				return "left"
+			case 'H':
+				return "home"
+			case 'F':
+				return "end"
+			}
+			if len(buf) >= 4 && buf[3] == '~' {
+				switch buf[2] {
+				case '1', '7':
+					return "home"
+				case '4', '8':
+					return "end"
+				case '5':
+					return "pageup"
+				case '6':
+					return "pagedown"
+				}
+			}
+		}
+		return ""
+	}
+
+	if len(buf) == 1 {
+		switch b := buf[0]; {
+		case b == 13 || b == 10:
+			return "enter"
+		case b == 9:
+			return "tab"
+		case b == 32:
+			return "space"
+		case b == 127 || b == 8:
+			return "backspace"
+		case b >= 1 && b <= 26:
+			return "ctrl-" + string(rune('a'+b-1))
+		case b < 32:
+			return ""
+		}
+	}
+
+	r, _ := utf8.DecodeRune(buf)
+	if r == utf8.RuneError {
+		return ""
+	}
+	return string(r)
+}
+
+// readKey reads a single key press from the terminal in raw mode
+func readKey(f *os.File) (string, error) {
+	buffer := make([]byte, 8)
+	n, err := f.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	return decodeKey(buffer[:n]), nil
+}
+
+// errKeyTimeout is returned by keyReader.next when no key was pressed in time
+var errKeyTimeout = errors.New("key timeout")
+
+// keyReader reads key presses from the terminal in raw mode
+type keyReader struct {
+	file      *os.File
+	fd        int
+	deadlines bool // The file supports read deadlines, so reads can time out
+}
+
+// newKeyReader creates a key reader for the terminal. When standard input is a
+// terminal, /dev/tty is opened separately because read deadlines only work on
+// files opened by the process; otherwise keys are read from standard input
+// without timeouts. The returned function closes the reader.
+func newKeyReader() (*keyReader, func()) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+			// File.Fd would switch the file to blocking mode and disable deadlines
+			if fd, ok := rawFd(tty); ok && tty.SetReadDeadline(time.Time{}) == nil {
+				return &keyReader{file: tty, fd: fd, deadlines: true}, func() { tty.Close() }
+			}
+			tty.Close()
+		}
+	}
+	return &keyReader{file: os.Stdin, fd: int(os.Stdin.Fd())}, func() {}
+}
+
+// rawFd returns the file descriptor of f without changing its blocking mode
+func rawFd(f *os.File) (int, bool) {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+	fd := -1
+	if err := conn.Control(func(p uintptr) { fd = int(p) }); err != nil {
+		return 0, false
+	}
+	return fd, fd >= 0
+}
+
+// next waits for a key press. A positive timeout makes it return errKeyTimeout
+// when no key arrives in time; without deadline support it waits indefinitely.
+func (r *keyReader) next(timeout time.Duration) (string, error) {
+	oldState, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", fmt.Errorf("entering raw mode: %w", err)
+	}
+	defer term.Restore(r.fd, oldState)
+
+	if r.deadlines {
+		deadline := time.Time{}
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		r.file.SetReadDeadline(deadline)
+	}
+
+	key, err := readKey(r.file)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return "", errKeyTimeout
+	}
+	return key, err
+}
+
+// printKeyBindings prints the active key bindings for the interactive help
+func printKeyBindings() {
+	descriptions := map[string]string{
+		ActionUp:         messages.ActionUpDesc,
+		ActionDown:       messages.ActionDownDesc,
+		ActionSelect:     messages.ActionSelectDesc,
+		ActionAdd:        messages.ActionAddDesc,
+		ActionHelp:       messages.ActionHelpDesc,
+		ActionExit:       messages.ActionExitDesc,
+		ActionSearch:     messages.ActionSearchDesc,
+		ActionTag:        messages.ActionTagDesc,
+		ActionSwitchMode: messages.ActionSwitchModeDesc,
+	}
+
+	fmt.Printf("\n%s\n", messages.KeyBindingsTitle)
+	for _, action := range menuActions {
+		keys := keyBindings.label(action)
+		fmt.Printf("  %-12s %-14s %s\n", action, keys, descriptions[action])
+	}
+}
+
+// cursorModeHint returns the cursor mode hint built from the active key bindings
+func cursorModeHint() string {
+	move := keyBindings.label(ActionUp) + " " + keyBindings.label(ActionDown)
+	return fmt.Sprintf(messages.CursorModeHint, move, keyBindings.label(ActionSelect), keyBindings.label(ActionSwitchMode))
+}
+
+// cursorActionsHint returns the cursor mode action hint built from the active key bindings
+func cursorActionsHint() string {
+	return fmt.Sprintf(messages.CursorActionsHint,
+		keyBindings.label(ActionHelp), keyBindings.label(ActionSearch),
+		keyBindings.label(ActionExit), keyBindings.label(ActionAdd))
+}
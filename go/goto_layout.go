@@ -0,0 +1,306 @@
+// goto_layout.go - Column layout engine for destination tables
+// This file contains the table renderer shared by the interactive menu,
+// --list and --history. Widths are measured in terminal cells with
+// getDisplayWidth, so wide (East Asian) characters stay aligned.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Column names available in the columns and history_columns settings
+const (
+	ColumnNumber      = "number"
+	ColumnLabel       = "label"
+	ColumnShortcut    = "shortcut"
+	ColumnTags        = "tags"
+	ColumnLastUsed    = "last-used" // Relative time, e.g. "3h ago"
+	ColumnUsedAt      = "used-at"   // Absolute timestamp
+	ColumnPath        = "path"
+	ColumnDescription = "description"
+)
+
+// availableColumns lists all column names
+var availableColumns = []string{
+	ColumnNumber, ColumnStatus, ColumnLabel, ColumnShortcut, ColumnTags,
+	ColumnLastUsed, ColumnUsedAt, ColumnPath, ColumnDescription,
+}
+
+// Default columns for the menu and --list, and for --history
+var (
+	defaultColumns        = []string{ColumnNumber, ColumnStatus, ColumnLabel, ColumnShortcut, ColumnPath}
+	defaultHistoryColumns = []string{ColumnNumber, ColumnLabel, ColumnUsedAt, ColumnPath}
+)
+
+// Maximum widths of columns that are not the last column (when truncating)
+const (
+	defaultLabelWidth = 20
+	maxPathWidth      = 40
+	maxTextWidth      = 30
+	minLastWidth      = 8
+)
+
+// tableCell is a single cell of a table row
+type tableCell struct {
+	text  string
+	style string
+}
+
+// tableRow is a row of a table keyed by column name
+type tableRow struct {
+	cells    map[string]tableCell
+	selected bool
+}
+
+// tableLayout renders rows into aligned lines
+type tableLayout struct {
+	columns    []string
+	width      int // Terminal width; 0 means unlimited
+	labelWidth int
+	cursorMode bool // Reserve the selection marker column when colours are off
+}
+
+// validateColumns checks that all column names are known
+func validateColumns(columns []string) error {
+	for _, column := range columns {
+		known := false
+		for _, name := range availableColumns {
+			if column == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown column %q (available: %s)", column, strings.Join(availableColumns, ", "))
+		}
+	}
+	return nil
+}
+
+// newTableLayout creates a layout for the columns sized to the terminal.
+// When truncate is false, long cells are never shortened.
+func newTableLayout(columns []string, truncate bool) tableLayout {
+	layout := tableLayout{columns: columns, labelWidth: appSettings.LabelWidth}
+	if truncate {
+		layout.width, _ = terminalSize()
+	}
+	return layout
+}
+
+// entryRow builds a table row for an entry
+func entryRow(entry Entry, number string) tableRow {
+	expandedPath := expandPath(entry.Path)
+	label := entry.Label
+	if entry.Scope != "" {
+		label = localMarker + label
+	}
+	cells := map[string]tableCell{
+		ColumnNumber:      {text: number},
+		ColumnLabel:       {text: label, style: theme.Label},
+		ColumnStatus:      {text: entry.Status.indicator()},
+		ColumnPath:        {text: expandedPath, style: theme.pathStyle(entry)},
+		ColumnDescription: {text: entry.Description},
+		ColumnLastUsed:    {text: formatRelativeTime(entry.LastUsed)},
+		ColumnUsedAt:      {text: formatTimestamp(entry.LastUsed)},
+	}
+	if entry.Shortcut != "" {
+		cells[ColumnShortcut] = tableCell{text: "(" + entry.Shortcut + ")", style: theme.Shortcut}
+	}
+	if len(entry.Tags) > 0 {
+		cells[ColumnTags] = tableCell{text: "#" + strings.Join(entry.Tags, " #"), style: theme.Shortcut}
+	}
+	return tableRow{cells: cells}
+}
+
+// formatRelativeTime formats the time elapsed since t, e.g. "3h ago"
+func formatRelativeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return messages.TimeJustNow
+	case d < time.Hour:
+		return fmt.Sprintf(messages.TimeMinutesAgo, int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf(messages.TimeHoursAgo, int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf(messages.TimeDaysAgo, int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf(messages.TimeMonthsAgo, int(d.Hours()/24/30))
+	}
+	return fmt.Sprintf(messages.TimeYearsAgo, int(d.Hours()/24/365))
+}
+
+// formatTimestamp formats an absolute timestamp in the format of the language
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	layout := messages.DateTimeFormat
+	if layout == "" {
+		layout = "2006-01-02 15:04:05"
+	}
+	return t.Local().Format(layout)
+}
+
+// columnMaxWidth returns the maximum width of a column that is not the last column
+func (l tableLayout) columnMaxWidth(column string) int {
+	switch column {
+	case ColumnLabel:
+		if l.labelWidth > 0 {
+			return l.labelWidth
+		}
+		return defaultLabelWidth
+	case ColumnPath:
+		return maxPathWidth
+	case ColumnTags, ColumnDescription:
+		return maxTextWidth
+	}
+	return 0
+}
+
+// separator returns the text placed before a column
+func separator(column string, first bool) string {
+	if first {
+		return ""
+	}
+	if column == ColumnPath {
+		return " → "
+	}
+	return " "
+}
+
+// render lays out the rows and returns one line per row
+func (l tableLayout) render(rows []tableRow) []string {
+	// Hide columns that are empty in every row
+	var columns []string
+	for _, column := range l.columns {
+		for _, row := range rows {
+			if row.cells[column].text != "" {
+				columns = append(columns, column)
+				break
+			}
+		}
+	}
+
+	// Measure columns
+	widths := make(map[string]int)
+	for _, column := range columns {
+		for _, row := range rows {
+			if w := getDisplayWidth(row.cells[column].text); w > widths[column] {
+				widths[column] = w
+			}
+		}
+	}
+
+	markerWidth := getDisplayWidth(cursorMarker(l.cursorMode, false))
+	used := markerWidth
+	for i, column := range columns {
+		if i == len(columns)-1 {
+			break
+		}
+		if limit := l.columnMaxWidth(column); l.width > 0 && limit > 0 && widths[column] > limit {
+			widths[column] = limit
+		}
+		used += getDisplayWidth(separator(column, i == 0)) + widths[column]
+	}
+
+	// The last column takes the remaining width
+	if n := len(columns); n > 0 {
+		last := columns[n-1]
+		if l.width > 0 {
+			remaining := l.width - used - getDisplayWidth(separator(last, n == 1))
+			if remaining < minLastWidth {
+				remaining = minLastWidth
+			}
+			if widths[last] > remaining {
+				widths[last] = remaining
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		// Trailing empty cells are not written
+		lastFilled := -1
+		for i, column := range columns {
+			if row.cells[column].text != "" {
+				lastFilled = i
+			}
+		}
+
+		var plain, styled strings.Builder
+		for i, column := range columns {
+			if i > lastFilled {
+				break
+			}
+			cell := row.cells[column]
+			text := fitCell(column, cell.text, widths[column])
+			pad := widths[column] - getDisplayWidth(text)
+			sep := separator(column, i == 0)
+			if cell.text == "" {
+				sep = strings.Repeat(" ", getDisplayWidth(sep))
+			}
+
+			plain.WriteString(sep)
+			styled.WriteString(sep)
+			switch {
+			case column == ColumnNumber:
+				plain.WriteString(strings.Repeat(" ", pad) + text)
+				styled.WriteString(strings.Repeat(" ", pad) + theme.paint(cell.style, text))
+			case i == lastFilled:
+				plain.WriteString(text)
+				styled.WriteString(theme.paint(cell.style, text))
+			default:
+				plain.WriteString(text + strings.Repeat(" ", pad))
+				styled.WriteString(theme.paint(cell.style, text) + strings.Repeat(" ", pad))
+			}
+		}
+
+		if row.selected {
+			lines = append(lines, selectedLine(plain.String()))
+		} else {
+			lines = append(lines, cursorMarker(l.cursorMode, false)+styled.String())
+		}
+	}
+	return lines
+}
+
+// fitCell shortens the text of a cell to the width
+func fitCell(column, text string, width int) string {
+	if getDisplayWidth(text) <= width {
+		return text
+	}
+	if column == ColumnPath {
+		return shortenPathMiddle(text, width)
+	}
+	return truncateToWidth(text, width)
+}
+
+// truncateToWidth cuts text to the display width, ending it with an ellipsis
+func truncateToWidth(text string, width int) string {
+	if getDisplayWidth(text) <= width {
+		return text
+	}
+	ellipsis := "..."
+	if width <= len(ellipsis) {
+		ellipsis = ""
+	}
+
+	var b strings.Builder
+	current := 0
+	for _, r := range text {
+		w := getDisplayWidth(string(r))
+		if current+w > width-len(ellipsis) {
+			break
+		}
+		b.WriteRune(r)
+		current += w
+	}
+	return b.String() + ellipsis
+}
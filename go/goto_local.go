@@ -0,0 +1,197 @@
+// goto_local.go - Repository-local destinations
+// This file contains the discovery of .goto.toml files in the current
+// directory and its parents, e.g. per-repository bookmarks checked in with a
+// monorepo. Their destinations are merged into the menu with the name of the
+// directory holding the file as scope: [api] in ~/src/mono/.goto.toml becomes
+// "mono:api", so two repositories can both define "api". Relative paths are
+// resolved against that directory.
+//
+// Commands of local destinations come from files anyone with commit access
+// can change, so they only run once trusted (see goto_trust.go). For the same
+// reason personal shortcuts win: a local shortcut that equals one, or starts
+// or extends one, is dropped with a warning.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	localConfigName = ".goto.toml"
+	localMarker     = "⌂ " // Shown before local labels in tables
+
+	// Config order of local destinations: after the personal ones, nearest file first
+	localOrderStride = 1 << 16
+)
+
+// localsEnabled reports whether .goto.toml files are discovered
+func (g GeneralSettings) localsEnabled() bool {
+	return g.LocalConfigs == nil || *g.LocalConfigs
+}
+
+// findLocalConfigs returns the .goto.toml files in dir and its parents,
+// nearest first. The personal configuration files are skipped, as
+// ~/.goto.toml is found from anywhere below the home directory.
+func findLocalConfigs(dir, tomlFile string) []string {
+	personal := map[string]bool{
+		canonicalPath(tomlFile):                         true,
+		canonicalPath(legacyConfigPath(defaultProfile)): true,
+	}
+
+	var files []string
+	for dir = canonicalPath(dir); ; dir = filepath.Dir(dir) {
+		file := filepath.Join(dir, localConfigName)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() && !personal[canonicalPath(file)] {
+			files = append(files, file)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return files
+}
+
+// localScope returns the scope of the destinations in a local file
+func localScope(file string) string {
+	return filepath.Base(filepath.Dir(file))
+}
+
+// scopedLabel returns the label of a local destination, e.g. "mono:api"
+func scopedLabel(scope, label string) string {
+	return scope + ":" + label
+}
+
+// loadLocalDestinations returns the destinations of the .goto.toml files
+// above the current directory, keyed by their scoped label. A file that
+// cannot be read is reported and skipped; its settings sections are ignored.
+func loadLocalDestinations(tomlFile string) map[string]Destination {
+	destinations := make(map[string]Destination)
+	if !appSettings.localsEnabled() {
+		return destinations
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return destinations
+	}
+
+	for i, file := range findLocalConfigs(cwd, tomlFile) {
+		config, _, err := loadConfigSource(file, true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", messages.WarningLocalConfig, file, err)
+			continue
+		}
+		scope, base := localScope(file), filepath.Dir(file)
+		for label, dest := range config {
+			key := scopedLabel(scope, label)
+			if _, exists := destinations[key]; exists {
+				continue // A nearer file with the same scope wins
+			}
+			dest.Path = resolveLocalPath(base, dest.Path)
+			dest.Scope, dest.Source = scope, file
+			dest.Order += (i + 1) * localOrderStride
+			dest.ID = 0 // Stable IDs of shared files could clash with personal ones
+			destinations[key] = dest
+		}
+	}
+	return destinations
+}
+
+// resolveLocalPath resolves a relative path of a local destination against
+// the directory of its file
+func resolveLocalPath(base, path string) string {
+	if path == "" || IsURL(path) || strings.HasPrefix(path, "~") || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// localShortcutClash is a local shortcut dropped for a personal one
+type localShortcutClash struct {
+	label, shortcut, owner string
+}
+
+// localShortcutClashes holds the clashes of the last merge, reported on
+// stderr and by "goto check"
+var localShortcutClashes []localShortcutClash
+
+// mergeLocalDestinations adds the local destinations to the configuration,
+// dropping their shortcuts that clash with personal ones
+func mergeLocalDestinations(config map[string]Destination, tomlFile string) {
+	personal := make(map[string]string)
+	for label, dest := range config {
+		if dest.Shortcut != "" {
+			personal[dest.Shortcut] = label
+		}
+	}
+
+	localShortcutClashes = nil
+	for label, dest := range loadLocalDestinations(tomlFile) {
+		if _, exists := config[label]; exists {
+			continue
+		}
+		if owner := shortcutOwner(personal, dest.Shortcut); owner != "" {
+			localShortcutClashes = append(localShortcutClashes, localShortcutClash{label, dest.Shortcut, owner})
+			dest.Shortcut = ""
+		}
+		config[label] = dest
+	}
+	sort.Slice(localShortcutClashes, func(i, j int) bool {
+		return localShortcutClashes[i].label < localShortcutClashes[j].label
+	})
+}
+
+// shortcutOwner returns the label owning a shortcut that equals the given one
+// or is a prefix of it or it of them, so that neither could be typed as before
+func shortcutOwner(owners map[string]string, shortcut string) string {
+	if shortcut == "" {
+		return ""
+	}
+	var found []string
+	for other, label := range owners {
+		if strings.HasPrefix(other, shortcut) || strings.HasPrefix(shortcut, other) {
+			found = append(found, label)
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	sort.Strings(found)
+	return found[0]
+}
+
+// warnLocalShortcutClashes prints the dropped local shortcuts on stderr
+func warnLocalShortcutClashes() {
+	for _, clash := range localShortcutClashes {
+		fmt.Fprintf(os.Stderr, messages.WarningLocalShortcut+"\n", clash.shortcut, clash.label, clash.owner)
+	}
+}
+
+// localShortcutProblems returns the dropped local shortcuts for "goto check"
+func localShortcutProblems() []string {
+	var problems []string
+	for _, clash := range localShortcutClashes {
+		problems = append(problems, fmt.Sprintf(messages.CheckLocalShortcut, clash.label, clash.shortcut, clash.owner))
+	}
+	return problems
+}
+
+// findLocalByName returns the local entry whose unscoped label is name. When
+// several scopes define it, the one from the nearest file wins.
+func findLocalByName(entries []Entry, name string) (Entry, bool) {
+	var best Entry
+	found := false
+	for _, entry := range entries {
+		if entry.Scope == "" || !strings.EqualFold(strings.TrimPrefix(entry.Label, entry.Scope+":"), name) {
+			continue
+		}
+		if !found || len(entry.Source) > len(best.Source) {
+			best, found = entry, true
+		}
+	}
+	return best, found
+}
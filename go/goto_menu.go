@@ -0,0 +1,410 @@
+// goto_menu.go - Interactive cursor mode menu
+// This file contains the state machine of the cursor mode menu,
+// which maps key presses to menu actions, and the terminal loop driving it.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultDigitTimeout is how long cursor mode waits for another digit (or
+// letter) when the number (or shortcut) typed so far is also the prefix of a
+// longer valid one
+const defaultDigitTimeout = 700 * time.Millisecond
+
+// menuOutcome tells the menu loop what to do after a key press
+type menuOutcome int
+
+const (
+	menuContinue   menuOutcome = iota // Nothing changed
+	menuRedraw                        // The screen needs to be redrawn
+	menuChosen                        // An entry was chosen
+	menuCancel                        // The menu was cancelled
+	menuAddCurrent                    // Add the current directory
+	menuSwitchMode                    // Switch to label input mode
+	menuShowHelp                      // Show the help screen
+)
+
+// cursorMenu holds the state of the cursor mode menu
+type cursorMenu struct {
+	entries     []Entry
+	shortcutMap map[string]int
+	view        []int  // Indices of entries currently shown
+	selected    int    // Position in view; len(view) selects Exit
+	chosen      int    // Index of the chosen entry
+	inputBuffer string // Digits typed so far
+	keyBuffer   string // Letters of a multi-key shortcut typed so far
+	searching   bool
+	query       string
+	tags        []string // Tags of the entries, cycled through by the tag key
+	tag         string   // Only entries with this tag are shown; "" shows all
+}
+
+// newCursorMenu creates a cursor menu showing all entries
+func newCursorMenu(entries []Entry, shortcutMap map[string]int) *cursorMenu {
+	m := &cursorMenu{entries: entries, shortcutMap: shortcutMap, chosen: -1, tags: listTags(entries)}
+	m.applyFilter()
+	return m
+}
+
+// applyFilter rebuilds the view from the search query and the tag
+func (m *cursorMenu) applyFilter() {
+	m.view = m.view[:0]
+	query := strings.ToLower(m.query)
+	for i, entry := range m.entries {
+		if m.tag != "" && !hasTag(entry.Tags, m.tag) {
+			continue
+		}
+		if m.keyBuffer != "" && !strings.HasPrefix(entry.Shortcut, m.keyBuffer) {
+			continue
+		}
+		if query == "" ||
+			strings.Contains(strings.ToLower(entry.Label), query) ||
+			aliasContains(entry, query) ||
+			strings.Contains(strings.ToLower(expandPath(entry.Path)), query) ||
+			strings.EqualFold(entry.Shortcut, m.query) {
+			m.view = append(m.view, i)
+		}
+	}
+	if appSettings.GroupByTag {
+		groupByTag(m.entries, m.view)
+	}
+	if m.selected > len(m.view) {
+		m.selected = len(m.view)
+	}
+	if m.searching && m.selected == len(m.view) && len(m.view) > 0 {
+		m.selected = 0
+	}
+}
+
+// choose marks the entry at index as chosen
+func (m *cursorMenu) choose(index int) menuOutcome {
+	m.chosen = index
+	return menuChosen
+}
+
+// choice returns the target directory, command and label of the chosen entry
+func (m *cursorMenu) choice() (string, string, string) {
+	entry := m.entries[m.chosen]
+	return expandPath(entry.Path), entry.Command, entry.Label
+}
+
+// move moves the selection by delta within the view and the Exit line
+func (m *cursorMenu) move(delta int) menuOutcome {
+	next := m.selected + delta
+	if next < 0 || next > len(m.view) {
+		return menuContinue
+	}
+	m.selected = next
+	return menuRedraw
+}
+
+// selectCurrent chooses the selected entry, or cancels when Exit is selected
+func (m *cursorMenu) selectCurrent() menuOutcome {
+	if m.selected >= len(m.view) {
+		return menuCancel
+	}
+	return m.choose(m.view[m.selected])
+}
+
+// handleKey updates the menu state for a key press
+func (m *cursorMenu) handleKey(key string) menuOutcome {
+	if m.searching {
+		return m.handleSearchKey(key)
+	}
+
+	// While a shortcut is being typed, keys extend it even if they are bound
+	// (e.g. "j" moves down, but "g" "j" selects the shortcut "gj")
+	if m.keyBuffer != "" {
+		if utf8.RuneCountInString(key) == 1 && m.hasShortcutPrefix(m.keyBuffer+key) {
+			return m.typeShortcutKey(key)
+		}
+		if keyBindings.action(key) == ActionSelect {
+			return m.confirmShortcut()
+		}
+		m.clearShortcut()
+		if key == "esc" {
+			return menuRedraw
+		}
+	}
+
+	// While a number is being typed, digits extend it even if they are bound
+	// (e.g. "0" exits the menu, but "1" "0" selects entry 10)
+	if isDigitKey(key) && (m.inputBuffer != "" || keyBindings.action(key) == "") {
+		return m.typeDigit(key)
+	}
+	if m.inputBuffer != "" && keyBindings.action(key) == ActionSelect {
+		return m.confirmNumber()
+	}
+
+	switch keyBindings.action(key) {
+	case ActionUp:
+		m.inputBuffer = ""
+		return m.move(-1)
+	case ActionDown:
+		m.inputBuffer = ""
+		return m.move(1)
+	case ActionSelect:
+		return m.selectCurrent()
+	case ActionAdd:
+		return menuAddCurrent
+	case ActionHelp:
+		return menuShowHelp
+	case ActionExit:
+		return menuCancel
+	case ActionSearch:
+		m.inputBuffer = ""
+		m.searching = true
+		m.query = ""
+		m.selected = 0
+		m.applyFilter()
+		return menuRedraw
+	case ActionTag:
+		if len(m.tags) == 0 {
+			return menuContinue
+		}
+		m.inputBuffer = ""
+		m.tag = nextTag(m.tags, m.tag)
+		m.selected = 0
+		m.applyFilter()
+		return menuRedraw
+	case ActionSwitchMode:
+		return menuSwitchMode
+	}
+
+	// Shortcut keys select entries immediately, unless a longer shortcut starts with them
+	pending := m.inputBuffer != ""
+	m.inputBuffer = ""
+	if utf8.RuneCountInString(key) == 1 && m.hasShortcutPrefix(key) {
+		return m.typeShortcutKey(key)
+	}
+	if pending {
+		return menuRedraw
+	}
+	return menuContinue
+}
+
+// hasShortcutPrefix reports whether a shortcut starts with the letters
+func (m *cursorMenu) hasShortcutPrefix(typed string) bool {
+	exact, longer := shortcutMatches(m.shortcutMap, typed)
+	return exact || longer
+}
+
+// typeShortcutKey appends a key to the shortcut being typed. The entry is
+// chosen at once when no longer shortcut starts with the keys typed so far;
+// otherwise the menu narrows to the candidates and waits for more keys,
+// Enter or the digit timeout.
+func (m *cursorMenu) typeShortcutKey(key string) menuOutcome {
+	typed := m.keyBuffer + key
+	exact, longer := shortcutMatches(m.shortcutMap, typed)
+	if exact && !longer {
+		m.clearShortcut()
+		return m.choose(m.shortcutMap[typed] - 1)
+	}
+	m.keyBuffer = typed
+	m.selected = 0
+	m.applyFilter()
+	return menuRedraw
+}
+
+// confirmShortcut chooses the entry whose shortcut has been typed
+func (m *cursorMenu) confirmShortcut() menuOutcome {
+	index, exists := m.shortcutMap[m.keyBuffer]
+	m.clearShortcut()
+	if !exists {
+		return menuRedraw
+	}
+	return m.choose(index - 1)
+}
+
+// clearShortcut forgets the shortcut being typed and shows all entries again
+func (m *cursorMenu) clearShortcut() {
+	if m.keyBuffer == "" {
+		return
+	}
+	m.keyBuffer = ""
+	m.applyFilter()
+}
+
+// isDigitKey reports whether the key is a decimal digit
+func isDigitKey(key string) bool {
+	return len(key) == 1 && key[0] >= '0' && key[0] <= '9'
+}
+
+// typeDigit appends a digit to the number being typed. The entry is chosen
+// at once when no longer valid number starts with the digits typed so far;
+// otherwise the menu waits for more digits, Enter or the digit timeout.
+func (m *cursorMenu) typeDigit(key string) menuOutcome {
+	exact, longer := numberPrefixes(m.entries, m.inputBuffer+key)
+	if !exact && !longer {
+		m.inputBuffer = ""
+		return menuRedraw
+	}
+	m.inputBuffer += key
+	if !longer {
+		return m.confirmNumber()
+	}
+	return menuRedraw
+}
+
+// confirmNumber chooses the entry whose number has been typed
+func (m *cursorMenu) confirmNumber() menuOutcome {
+	index := findEntryByNumber(m.entries, m.inputBuffer)
+	m.inputBuffer = ""
+	if index < 0 {
+		return menuRedraw
+	}
+	return m.choose(index)
+}
+
+// handleTimeout is called when no key was pressed within the digit timeout
+func (m *cursorMenu) handleTimeout() menuOutcome {
+	switch {
+	case m.inputBuffer != "":
+		return m.confirmNumber()
+	case m.keyBuffer != "":
+		return m.confirmShortcut()
+	}
+	return menuContinue
+}
+
+// pending reports whether a number or shortcut is being typed
+func (m *cursorMenu) pending() bool {
+	return m.inputBuffer != "" || m.keyBuffer != ""
+}
+
+// keyTimeout returns how long to wait for the next key; 0 waits indefinitely
+func (m *cursorMenu) keyTimeout() time.Duration {
+	if !m.pending() {
+		return 0
+	}
+	return appSettings.digitTimeout()
+}
+
+// handleSearchKey updates the search query for a key press
+func (m *cursorMenu) handleSearchKey(key string) menuOutcome {
+	switch key {
+	case "esc":
+		m.searching = false
+		m.query = ""
+		m.applyFilter()
+		return menuRedraw
+	case "enter":
+		if len(m.view) == 0 {
+			return menuContinue
+		}
+		return m.selectCurrent()
+	case "up":
+		return m.move(-1)
+	case "down":
+		return m.move(1)
+	case "backspace":
+		if m.query == "" {
+			return menuContinue
+		}
+		_, size := utf8.DecodeLastRuneInString(m.query)
+		m.query = m.query[:len(m.query)-size]
+	case "space":
+		m.query += " "
+	default:
+		if utf8.RuneCountInString(key) != 1 {
+			return menuContinue
+		}
+		m.query += key
+	}
+	m.selected = 0
+	m.applyFilter()
+	return menuRedraw
+}
+
+// redraw clears the screen and draws the menu
+func (m *cursorMenu) redraw() {
+	fmt.Print("\033[2J\033[H") // Clear the screen and move the cursor to the top left
+	termWidth, termHeight := terminalSize()
+	m.render(os.Stdout, termWidth, termHeight)
+}
+
+// render writes the menu for a screen of the given size
+func (m *cursorMenu) render(w io.Writer, termWidth, termHeight int) {
+	title := menuTitle()
+	if m.tag != "" {
+		title += " " + fmt.Sprintf(messages.TagIndicator, "#"+m.tag)
+	}
+	fmt.Fprintln(w, headerLine(title, termWidth))
+
+	if len(m.view) == 0 {
+		fmt.Fprintln(w, messages.NoMatchingDestinations)
+	}
+	writeEntries(w, m.entries, m.view, m.selected, true, termWidth, termHeight)
+
+	fmt.Fprintln(w, strings.Repeat("-", termWidth))
+	if m.searching {
+		fmt.Fprintln(w, messages.SearchHint)
+		fmt.Fprintf(w, "%s %s\n", messages.SearchPrompt, m.query)
+		return
+	}
+	if len(m.tags) > 0 {
+		fmt.Fprintln(w, cursorActionsHint()+fmt.Sprintf(messages.TagCycleHint, keyBindings.label(ActionTag)))
+	} else {
+		fmt.Fprintln(w, cursorActionsHint())
+	}
+	fmt.Fprintln(w, cursorModeHint())
+	if m.inputBuffer != "" {
+		fmt.Fprintf(w, messages.PendingNumber+"\n", m.inputBuffer)
+	}
+	if m.keyBuffer != "" {
+		candidates := shortcutCandidates(m.shortcutMap, m.keyBuffer)
+		fmt.Fprintf(w, messages.PendingShortcut+"\n", m.keyBuffer, strings.Join(candidates, " "))
+	}
+}
+
+// getUserChoiceCursorMode lets the user choose a destination with the cursor
+func getUserChoiceCursorMode(entries []Entry, shortcutMap map[string]int, tomlFile string) (string, string, string) {
+	menu := newCursorMenu(entries, shortcutMap)
+	menu.redraw()
+
+	// Warn below the first frame about shortcuts that cannot be typed in the menu
+	for _, warning := range validateKeyBindings(keyBindings, entries) {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	reader, closeReader := newKeyReader()
+	defer closeReader()
+
+	for {
+		var outcome menuOutcome
+		key, err := reader.next(menu.keyTimeout())
+		switch {
+		case err == errKeyTimeout:
+			outcome = menu.handleTimeout()
+		case err != nil:
+			fmt.Printf("%s %v\n", messages.ErrorReadingInput, err)
+			return "", "", ""
+		default:
+			outcome = menu.handleKey(key)
+		}
+
+		switch outcome {
+		case menuRedraw:
+			menu.redraw()
+		case menuChosen:
+			return menu.choice()
+		case menuCancel:
+			fmt.Printf("\n%s\n", messages.OperationCancelled)
+			return "", "", ""
+		case menuAddCurrent:
+			return "ADD_CURRENT", "", ""
+		case menuSwitchMode:
+			return getUserChoiceCmdMode(entries, shortcutMap, tomlFile)
+		case menuShowHelp:
+			showInteractiveHelp(tomlFile)
+			menu.redraw()
+		}
+	}
+}
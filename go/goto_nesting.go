@@ -0,0 +1,178 @@
+// goto_nesting.go - Nested goto shells
+// This file contains the environment of the shells opened by goto and the
+// policy for running goto inside such a shell. Every shell gets GOTO_DEPTH
+// (how many goto shells it is nested in) and GOTO_LABEL (the destination it
+// was opened for), so prompts can show them and users know how many exits
+// lead back.
+//
+// With nested_shell = "replace", goto run through the shell function asks
+// the goto process that opened the current shell to open the destination
+// instead, once the shell has exited:
+//
+//	goto (depth 0) -> shell (depth 1) -> goto web: writes handoff-<pid>.json, exits 75
+//	               <- the shell function exits the shell
+//	goto (depth 0) -> shell in web (depth 1)
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	depthEnvVar     = "GOTO_DEPTH"
+	labelEnvVar     = "GOTO_LABEL"
+	parentPidEnvVar = "GOTO_PARENT_PID"     // Parent process of the goto shell
+	functionEnvVar  = "GOTO_SHELL_FUNCTION" // Set by the shell function that runs goto
+
+	// Exit status asking the shell function to exit its shell after a handoff
+	handoffStatus = 75
+)
+
+// levelHandoff is a destination that replaces a goto shell at its depth
+type levelHandoff struct {
+	Dir     string `json:"dir"`
+	Command string `json:"command,omitempty"`
+	Label   string `json:"label,omitempty"`
+}
+
+// Policies for goto run inside a goto shell ([settings] nested_shell)
+const (
+	nestedNest    = "nest"    // Open a nested shell (default)
+	nestedWarn    = "warn"    // Open a nested shell and print a warning
+	nestedReplace = "replace" // Replace the current goto shell at its depth
+)
+
+// nestedPolicies lists the valid values of nested_shell
+var nestedPolicies = []string{nestedNest, nestedWarn, nestedReplace}
+
+// nestedShellPolicy returns the configured policy for nested goto shells
+func (g GeneralSettings) nestedShellPolicy() string {
+	if g.NestedShell == "" {
+		return nestedNest
+	}
+	return g.NestedShell
+}
+
+// validNestedPolicy reports whether the policy is known
+func validNestedPolicy(policy string) bool {
+	for _, p := range nestedPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedDepth returns the depth of the goto shell goto runs in (0 outside)
+func inheritedDepth() int {
+	depth, err := strconv.Atoi(os.Getenv(depthEnvVar))
+	if err != nil || depth < 0 {
+		return 0
+	}
+	return depth
+}
+
+// levelReplaced reports whether the goto shell that started goto has been
+// replaced by it ("exec goto ..."), so the new shell takes over its level
+func levelReplaced() bool {
+	pid, err := strconv.Atoi(os.Getenv(parentPidEnvVar))
+	return err == nil && pid == os.Getppid()
+}
+
+// shellDepth returns the depth of the shell goto is about to open
+func shellDepth() int {
+	depth := inheritedDepth()
+	if depth > 0 && levelReplaced() {
+		return depth
+	}
+	return depth + 1
+}
+
+// shellEnvironment returns the environment of a shell opened by goto.
+// parentPid is the process the shell will be a child of. GOTO_SHELL_FUNCTION
+// is removed, as only the shell function itself may set it.
+func shellEnvironment(label string, parentPid int) []string {
+	env := make([]string, 0, len(os.Environ())+4)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, functionEnvVar+"=") {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		sessionEnvVar+"="+sessionID(),
+		depthEnvVar+"="+strconv.Itoa(shellDepth()),
+		labelEnvVar+"="+label,
+		parentPidEnvVar+"="+strconv.Itoa(parentPid),
+	)
+}
+
+// warnNestedShell prints a warning when goto opens a shell inside a goto
+// shell and the policy asks for it
+func warnNestedShell() {
+	if depth := inheritedDepth(); depth > 0 && appSettings.nestedShellPolicy() == nestedWarn && !levelReplaced() {
+		fmt.Fprintf(os.Stderr, messages.WarningNestedShell+"\n", depth, depth+1)
+	}
+}
+
+// handOffLevel passes the destination to the goto process of the current
+// goto shell when the policy is "replace", and exits with handoffStatus so
+// that the shell function (see README) exits the shell. That goto process
+// then opens the destination at the same depth. Without the function goto
+// warns and returns; the caller then opens a nested shell.
+func handOffLevel(dir, command, label string) {
+	if inheritedDepth() == 0 || appSettings.nestedShellPolicy() != nestedReplace || levelReplaced() {
+		return
+	}
+	if os.Getenv(functionEnvVar) == "" {
+		fmt.Fprintf(os.Stderr, messages.WarningNoShellFunction+"\n", functionEnvVar)
+		return
+	}
+	pid, err := strconv.Atoi(os.Getenv(parentPidEnvVar))
+	if err == nil {
+		err = writeHandoff(handoffPath(pid), levelHandoff{Dir: dir, Command: command, Label: label})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningCannotReplaceShell, err)
+		return
+	}
+	os.Exit(handoffStatus)
+}
+
+// handoffPath returns the handoff file of the goto process with the pid
+func handoffPath(pid int) string {
+	return filepath.Join(stateDir(), fmt.Sprintf("handoff-%d.json", pid))
+}
+
+// writeHandoff stores the destination for the goto process of the shell
+func writeHandoff(path string, handoff levelHandoff) error {
+	data, err := json.Marshal(handoff)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// takeHandoff returns and removes the destination handed to this process by
+// the shell it opened
+func takeHandoff() (levelHandoff, bool) {
+	path := handoffPath(os.Getpid())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return levelHandoff{}, false
+	}
+	os.Remove(path)
+	var handoff levelHandoff
+	if err := json.Unmarshal(data, &handoff); err != nil || handoff.Dir == "" {
+		return levelHandoff{}, false
+	}
+	return handoff, true
+}
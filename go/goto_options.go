@@ -0,0 +1,133 @@
+// goto_options.go - Command line options
+// This file contains the registry of command line options and commands.
+// parseCommandLineArgs, the help text and the shell completion scripts are
+// all driven by it, so a new option only has to be added here.
+
+package main
+
+// Kinds of option values, used to complete them
+const (
+	valueNone    = ""
+	valueFile    = "FILE"
+	valueLang    = "LANG"
+	valueKeys    = "KEYS"
+	valueProfile = "PROFILE"
+	valueTag     = "TAG"
+	valueSort    = "SORT"
+)
+
+// cliOption is an option that may appear anywhere on the command line
+type cliOption struct {
+	Name  string                           // e.g. "--config-file"
+	Value string                           // Kind of the value; valueNone when the option takes none
+	Desc  func() string                    // Description shown in help and completion
+	Apply func(c *AppConfig, value string) // Stores the option in the configuration
+}
+
+// cliCommand is a command given as the first argument
+type cliCommand struct {
+	Names []string // e.g. "-h", "--help", "help"
+	Desc  func() string
+}
+
+// cliOptions lists all options accepted anywhere on the command line
+var cliOptions = []cliOption{
+	{"--config-file", valueFile, func() string { return messages.HelpConfigFile },
+		func(c *AppConfig, v string) { c.ConfigFile = v }},
+	{"--history-file", valueFile, func() string { return messages.HelpHistoryFile },
+		func(c *AppConfig, v string) { c.HistoryFile = v }},
+	{"--profile", valueProfile, func() string { return messages.HelpProfile },
+		func(c *AppConfig, v string) { c.Profile = v }},
+	{"--lang", valueLang, func() string { return messages.HelpLang },
+		func(c *AppConfig, v string) { c.Language = v }},
+	{"--tag", valueTag, func() string { return messages.HelpTag },
+		func(c *AppConfig, v string) { c.TagFilter = v }},
+	{"--sort", valueSort, func() string { return messages.HelpSort },
+		func(c *AppConfig, v string) { c.Sort = v }},
+	{"-c", valueNone, func() string { return messages.HelpCursorMode },
+		func(c *AppConfig, v string) { c.InteractiveMode = "cursor" }},
+	{"-l", valueNone, func() string { return messages.HelpLabelMode },
+		func(c *AppConfig, v string) { c.InteractiveMode = "label" }},
+	{"--keys", valueKeys, func() string { return messages.PlayBackKeys },
+		func(c *AppConfig, v string) { c.KeyList = v }},
+	{"--keys-file", valueFile, func() string { return messages.PlayBackKeys },
+		func(c *AppConfig, v string) { c.KeyFile = v }},
+	{"--snapshots", valueNone, func() string { return messages.HelpSnapshots },
+		func(c *AppConfig, v string) { c.Snapshots = true }},
+}
+
+// cliCommands lists the commands accepted as the first argument
+var cliCommands = []cliCommand{
+	{[]string{"-h", "--help", "help"}, func() string { return messages.ShowHelpMessage }},
+	{[]string{"-v", "--version", "version"}, func() string { return messages.ShowVersionInfo }},
+	{[]string{"--complete"}, func() string { return messages.ShowCompletionCandidates }},
+	{[]string{"--history"}, func() string { return messages.ShowRecentUsageHistory }},
+	{[]string{"--list"}, func() string { return messages.HelpList }},
+	{[]string{"--list-label"}, func() string { return messages.HelpListLabel }},
+	{[]string{"--add"}, func() string { return messages.AddCurrentDirectoryToConfig }},
+	{[]string{"pick"}, func() string { return messages.PickDestination }},
+	{[]string{"which"}, func() string { return messages.HelpWhich }},
+	{[]string{profileCommand}, func() string { return messages.HelpProfileCommand }},
+	{[]string{migratePathsCommand}, func() string { return messages.HelpMigratePaths }},
+	{[]string{backCommand, backStepsCommand}, func() string { return messages.HelpBack }},
+	{[]string{stackCommand}, func() string { return messages.HelpStack }},
+	{[]string{checkCommand}, func() string { return messages.HelpCheck }},
+	{[]string{trustCommand}, func() string { return messages.HelpTrust }},
+	{[]string{untrustCommand}, func() string { return messages.HelpUntrust }},
+	{[]string{"completion"}, func() string { return messages.HelpCompletion }},
+}
+
+// pickLabelOption is the option of "goto pick" that prints the label
+const pickLabelOption = "--label"
+
+// findOption returns the option with the name, or nil
+func findOption(name string) *cliOption {
+	for i := range cliOptions {
+		if cliOptions[i].Name == name {
+			return &cliOptions[i]
+		}
+	}
+	return nil
+}
+
+// optionNames returns the names of the options whose value is of the kind
+func optionNames(kind string) []string {
+	var names []string
+	for _, option := range cliOptions {
+		if option.Value == kind {
+			names = append(names, option.Name)
+		}
+	}
+	return names
+}
+
+// parseOptions applies the options in args to config and collects the other
+// arguments in config.FilteredArgs. An option missing its value is kept as
+// an argument.
+func parseOptions(config *AppConfig, args []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "pick" && !config.Pick && len(config.FilteredArgs) == 0 {
+			config.Pick = true
+			continue
+		}
+		if arg == pickLabelOption && config.Pick {
+			config.PickLabel = true
+			continue
+		}
+
+		option := findOption(arg)
+		if option == nil || (option.Value != valueNone && i+1 >= len(args)) {
+			config.FilteredArgs = append(config.FilteredArgs, arg)
+			continue
+		}
+
+		value := ""
+		if option.Value != valueNone {
+			value = args[i+1]
+			i++ // Skip the value of the option
+		}
+		option.Apply(config, value)
+	}
+}
@@ -0,0 +1,207 @@
+// goto_paths.go - Locations of the configuration and state files
+// This file decides where goto keeps its files. The configuration file is
+// looked up in this order:
+//
+//  1. --config-file FILE
+//  2. $GOTO_CONFIG
+//  3. $XDG_CONFIG_HOME/goto/config.toml (default ~/.config/goto/config.toml)
+//  4. ~/.goto.toml (legacy), when only this one exists
+//
+// and the history file likewise from --history-file, $GOTO_HISTORY,
+// $XDG_STATE_HOME/goto/history.json (default ~/.local/state/goto/history.json)
+// and ~/.goto.history.json. New files are created in the XDG directories;
+// "goto migrate-paths" moves the legacy files there.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	configEnvVar        = "GOTO_CONFIG"
+	historyEnvVar       = "GOTO_HISTORY"
+	migratePathsCommand = "migrate-paths"
+)
+
+// homeDir returns the home directory of the user or exits
+func homeDir() string {
+	usr, err := user.Current()
+	if err != nil {
+		fmt.Printf("%s %v\n", messages.ErrorGettingUser, err)
+		os.Exit(1)
+	}
+	return usr.HomeDir
+}
+
+// xdgDir returns $envVar/goto, or fallback/goto below the home directory
+// when the variable is unset or not absolute, as the XDG spec requires
+func xdgDir(envVar, fallback string) string {
+	if dir := os.Getenv(envVar); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "goto")
+	}
+	return filepath.Join(homeDir(), fallback, "goto")
+}
+
+// configDir returns the directory of the configuration files
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// stateDir returns the directory of the history and other state files
+func stateDir() string {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// cacheDir returns the directory of files that can be recreated at any time
+func cacheDir() string {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// preferExisting returns the XDG path unless only the legacy path exists
+func preferExisting(xdgPath, legacyPath string) string {
+	if !FileExists(xdgPath) && FileExists(legacyPath) {
+		return legacyPath
+	}
+	return xdgPath
+}
+
+// xdgConfigPath returns the configuration file of the profile in the XDG directory
+func xdgConfigPath(name string) string {
+	if name == defaultProfile {
+		return filepath.Join(configDir(), "config.toml")
+	}
+	return filepath.Join(configDir(), "profiles", name+".toml")
+}
+
+// legacyConfigPath returns the configuration file of the profile in the home directory
+func legacyConfigPath(name string) string {
+	if name == defaultProfile {
+		return filepath.Join(homeDir(), ".goto.toml")
+	}
+	return filepath.Join(homeDir(), ".goto."+name+".toml")
+}
+
+// xdgHistoryPath returns the history file of the profile in the XDG directory
+func xdgHistoryPath(name string) string {
+	if name == defaultProfile {
+		return filepath.Join(stateDir(), "history.json")
+	}
+	return filepath.Join(stateDir(), "profiles", name+".history.json")
+}
+
+// legacyHistoryPath returns the history file of the profile in the home directory
+func legacyHistoryPath(name string) string {
+	if name == defaultProfile {
+		return filepath.Join(homeDir(), ".goto.history.json")
+	}
+	return filepath.Join(homeDir(), ".goto."+name+".history.json")
+}
+
+// ensureParentDir creates the directory of the file if needed
+func ensureParentDir(file string) error {
+	return os.MkdirAll(filepath.Dir(file), 0755)
+}
+
+// displayPath shortens a path below the home directory to "~/..."
+func displayPath(path string) string {
+	home := homeDir()
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// stackPathOf returns the stack file stored next to a history file
+// ("history.json" -> "history.stack.json")
+func stackPathOf(historyFile string) string {
+	return strings.TrimSuffix(historyFile, filepath.Ext(historyFile)) + ".stack.json"
+}
+
+// legacyMoves returns the legacy files and where they belong in the XDG directories
+func legacyMoves() [][2]string {
+	profiles := []string{defaultProfile}
+	matches, _ := filepath.Glob(filepath.Join(homeDir(), ".goto.*.toml"))
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), ".goto."), ".toml")
+		if profileNamePattern.MatchString(name) && name != defaultProfile {
+			profiles = append(profiles, name)
+		}
+	}
+
+	moves := [][2]string{{legacySavedProfilePath(), xdgSavedProfilePath()}}
+	for _, name := range profiles {
+		moves = append(moves,
+			[2]string{legacyConfigPath(name), xdgConfigPath(name)},
+			[2]string{legacyHistoryPath(name), xdgHistoryPath(name)},
+			[2]string{stackPathOf(legacyHistoryPath(name)), stackPathOf(xdgHistoryPath(name))},
+		)
+	}
+	return moves
+}
+
+// migratePaths moves the legacy files to the XDG directories and exits.
+// Files whose new location is already taken are left alone.
+func migratePaths() {
+	moved, failed := 0, false
+	for _, move := range legacyMoves() {
+		from, to := move[0], move[1]
+		if !FileExists(from) {
+			continue
+		}
+		if FileExists(to) {
+			fmt.Printf(messages.MigrateSkipped+"\n", displayPath(from), displayPath(to))
+			failed = true
+			continue
+		}
+		if err := moveFile(from, to); err != nil {
+			fmt.Printf("%s %s: %v\n", messages.ErrorMigrating, displayPath(from), err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s %s → %s\n", messages.Migrated, displayPath(from), displayPath(to))
+		moved++
+	}
+
+	if moved == 0 && !failed {
+		fmt.Println(messages.NothingToMigrate)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// moveFile moves a file, copying it when it crosses file systems
+func moveFile(from, to string) error {
+	if err := ensureParentDir(to); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
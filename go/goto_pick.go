@@ -0,0 +1,61 @@
+// goto_pick.go - Picker mode
+// This file contains "goto pick", which draws the interactive menu on the
+// terminal (/dev/tty) and writes only the chosen path or label to stdout,
+// so goto can be used in pipelines: vim "$(goto pick)/main.go"
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// openTerminal opens the controlling terminal for reading and writing
+func openTerminal() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
+
+// redirectToTerminal makes the terminal the standard input and output of the
+// process, so the menu and prompts use it even when stdout is captured.
+// It returns the original standard output for the result.
+func redirectToTerminal() *os.File {
+	tty, err := openTerminal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.ErrorNoTerminal, err)
+		os.Exit(1)
+	}
+	result := os.Stdout
+	os.Stdin = tty
+	os.Stdout = tty
+	return result
+}
+
+// runPickMode shows the menu and writes the chosen path (or label) to out.
+// It exits with status 1 when nothing was chosen.
+func runPickMode(out *os.File, entries []Entry, shortcutMap map[string]int, tomlFile string, appConfig AppConfig) {
+	annotateStatuses(entries)
+	targetDir, _, label := getUserChoice(entries, shortcutMap, tomlFile, appConfig.InteractiveMode)
+
+	if targetDir == "ADD_CURRENT" {
+		addCurrentPathToConfig(tomlFile, appConfig.HistoryFile)
+		os.Exit(1)
+	}
+	if targetDir == "" {
+		os.Exit(1)
+	}
+
+	if label != "" {
+		if err := UpdateHistory(tomlFile, label, appConfig.HistoryFile); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningFailedToUpdateHistory, err)
+		}
+	}
+
+	// Clear the menu from the terminal before printing the result
+	fmt.Print("\033[2J\033[H")
+	if appConfig.PickLabel {
+		fmt.Fprintln(out, label)
+	} else {
+		fmt.Fprintln(out, targetDir)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,146 @@
+// goto_playback.go - Scripted key playback for the cursor menu
+// This file contains the headless mode (--keys, --keys-file) that runs the
+// state machine of the cursor menu against a virtual screen, so the menu and
+// custom key bindings can be tested without a terminal.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Size of the virtual screen used for playback
+const (
+	playbackWidth  = 80
+	playbackHeight = 24
+)
+
+// waitKey is a pseudo key in key scripts that lets the digit timeout elapse
+const waitKey = "wait"
+
+// parseKeyScript converts key names from a key script to canonical key names
+func parseKeyScript(names []string) ([]string, error) {
+	var keys []string
+	for _, name := range names {
+		if name == waitKey {
+			keys = append(keys, waitKey)
+			continue
+		}
+		key, err := normalizeKeyName(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// loadKeyScript returns the keys given with --keys ("down,down,enter") or
+// --keys-file (one key per line; blank lines and "# comments" are ignored)
+func loadKeyScript(keyList, keyFile string) ([]string, error) {
+	var names []string
+	if keyList != "" {
+		for _, name := range strings.Split(keyList, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	if keyFile != "" {
+		file, err := os.Open(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" || (strings.HasPrefix(line, "#") && len(line) > 1) {
+				continue
+			}
+			if line != " " {
+				line = strings.TrimSpace(line)
+			}
+			names = append(names, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return parseKeyScript(names)
+}
+
+// runKeyPlayback feeds the keys to the cursor menu and prints the result.
+// With snapshots, every rendered frame is printed before the result.
+// Nothing is opened, added or written to the history.
+func runKeyPlayback(entries []Entry, shortcutMap map[string]int, keys []string, snapshots bool) {
+	// Frames are plain text so they can be compared with expected output
+	theme.Enabled = false
+	annotateStatuses(entries)
+
+	menu := newCursorMenu(entries, shortcutMap)
+	frame := 0
+	snapshot := func(key string) {
+		if !snapshots {
+			return
+		}
+		if frame == 0 {
+			fmt.Printf("--- frame %d ---\n", frame)
+		} else {
+			fmt.Printf("--- frame %d: %s ---\n", frame, key)
+		}
+		menu.render(os.Stdout, playbackWidth, playbackHeight)
+		frame++
+	}
+
+	snapshot("")
+	outcome := menuContinue
+	closed := false
+	for _, key := range keys {
+		if key == waitKey {
+			outcome = menu.handleTimeout()
+		} else {
+			outcome = menu.handleKey(key)
+		}
+		if closed = menuClosed(outcome); closed {
+			break
+		}
+		snapshot(key)
+	}
+
+	// A pending number or shortcut is chosen when the script ends, as after the digit timeout
+	if !closed && menu.pending() {
+		outcome = menu.handleTimeout()
+	}
+
+	switch outcome {
+	case menuChosen:
+		targetDir, _, label := menu.choice()
+		fmt.Printf("chosen\t%s\t%s\n", label, targetDir)
+		os.Exit(0)
+	case menuCancel:
+		fmt.Println("cancelled")
+	case menuAddCurrent:
+		fmt.Println("add-current")
+	case menuSwitchMode:
+		fmt.Println("switch-mode")
+	default:
+		fmt.Println("none")
+	}
+	os.Exit(1)
+}
+
+// menuClosed reports whether the outcome closes the cursor menu
+func menuClosed(outcome menuOutcome) bool {
+	switch outcome {
+	case menuChosen, menuCancel, menuAddCurrent, menuSwitchMode:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+// goto_plugin.go - External subcommands
+// This file contains git-style plugins: "goto NAME ARGS..." runs an
+// executable goto-NAME found on PATH when NAME is not a built-in command.
+// Whether a destination or a plugin wins when both are named NAME is set by
+// plugin_precedence ("destinations" by default, "plugins" or "off").
+//
+// Plugins run with this environment:
+//
+//	GOTO_CONFIG   the configuration file in use
+//	GOTO_HISTORY  the history file in use
+//	GOTO_LANG     the language of the messages, e.g. "ja"
+//	GOTO_BIN      the goto executable, e.g. for "$GOTO_BIN --list --json"
+//
+// "goto --list --json" prints the resolved destinations for plugins.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const pluginPrefix = "goto-"
+
+// Values of the plugin_precedence setting
+const (
+	pluginsAfterDestinations  = "destinations" // Destinations win (default)
+	pluginsBeforeDestinations = "plugins"      // Plugins win
+	pluginsOff                = "off"          // Plugins are not run
+)
+
+// pluginPrecedences lists the values of the plugin_precedence setting
+var pluginPrecedences = []string{pluginsAfterDestinations, pluginsBeforeDestinations, pluginsOff}
+
+// validPluginPrecedence reports whether the value is a known precedence
+func validPluginPrecedence(value string) bool {
+	for _, precedence := range pluginPrecedences {
+		if value == precedence {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginPrecedence returns the configured precedence of plugins
+func (g GeneralSettings) pluginPrecedence() string {
+	if g.PluginPrecedence == "" {
+		return pluginsAfterDestinations
+	}
+	return g.PluginPrecedence
+}
+
+// findPlugin returns the executable of the plugin NAME on PATH, or ""
+func findPlugin(name string) string {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// listPlugins returns the names of the plugins on PATH, first one wins
+func listPlugins() []string {
+	found := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, pluginPrefix+"*"))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+				found[strings.TrimPrefix(filepath.Base(match), pluginPrefix)] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isBuiltinCommand reports whether the name is a built-in command, which
+// always wins over a plugin
+func isBuiltinCommand(name string) bool {
+	for _, command := range cliCommands {
+		for _, commandName := range command.Names {
+			if name == commandName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pluginShadowed returns why the plugin NAME cannot run, or "" when it can
+func pluginShadowed(name string, entries []Entry, shortcutMap map[string]int) string {
+	if isBuiltinCommand(name) {
+		return messages.PluginShadowedByCommand
+	}
+	if appSettings.pluginPrecedence() == pluginsAfterDestinations {
+		if targetDir, _, _ := findDestinationByArg(name, entries, shortcutMap); targetDir != "" {
+			return messages.PluginShadowedByDestination
+		}
+	}
+	return ""
+}
+
+// runPluginIfAny runs the plugin named by the first argument and exits,
+// unless plugins are off or a destination of that name wins
+func runPluginIfAny(args []string, entries []Entry, shortcutMap map[string]int, tomlFile, historyFile string) {
+	if appSettings.pluginPrecedence() == pluginsOff {
+		return
+	}
+	plugin := findPlugin(args[0])
+	if plugin == "" || pluginShadowed(args[0], entries, shortcutMap) != "" {
+		return
+	}
+
+	cmd := exec.Command(plugin, args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = pluginEnvironment(tomlFile, historyFile)
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Printf("%s %s: %v\n", messages.ErrorRunningPlugin, plugin, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// pluginEnvironment returns the environment of a plugin
+func pluginEnvironment(tomlFile, historyFile string) []string {
+	bin, err := os.Executable()
+	if err != nil {
+		bin = os.Args[0]
+	}
+	env := make([]string, 0, len(os.Environ())+4)
+	for _, kv := range os.Environ() {
+		name := kv[:strings.Index(kv, "=")+1]
+		if name != configEnvVar+"=" && name != historyEnvVar+"=" && name != "GOTO_LANG=" && name != "GOTO_BIN=" {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		configEnvVar+"="+tomlFile,
+		historyEnvVar+"="+historyFile,
+		"GOTO_LANG="+string(currentLanguage),
+		"GOTO_BIN="+bin,
+	)
+}
+
+// entryJSON is a destination in the output of "goto --list --json"
+type entryJSON struct {
+	Label       string     `json:"label"`
+	Path        string     `json:"path"` // Expanded path or URL
+	Shortcut    string     `json:"shortcut,omitempty"`
+	Aliases     []string   `json:"aliases,omitempty"`
+	Command     string     `json:"command,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Pinned      bool       `json:"pinned,omitempty"`
+	ID          int        `json:"id,omitempty"`     // Stable ID (stable_ids)
+	Scope       string     `json:"scope,omitempty"`  // Scope of a local destination
+	Source      string     `json:"source,omitempty"` // The .goto.toml file of a local destination
+	LastUsed    *time.Time `json:"last_used,omitempty"`
+}
+
+// showListJSON prints the destinations as a JSON array in menu order
+func showListJSON(entries []Entry) {
+	items := make([]entryJSON, 0, len(entries))
+	for _, entry := range entries {
+		item := entryJSON{
+			Label:       entry.Label,
+			Path:        expandPath(entry.Path),
+			Shortcut:    entry.Shortcut,
+			Aliases:     entry.Aliases,
+			Command:     entry.Command,
+			Description: entry.Description,
+			Tags:        entry.Tags,
+			Pinned:      entry.Pinned,
+			Scope:       entry.Scope,
+			Source:      entry.Source,
+		}
+		if stableIDsEnabled(entries) {
+			item.ID = entry.ID
+		}
+		if !entry.LastUsed.IsZero() {
+			lastUsed := entry.LastUsed
+			item.LastUsed = &lastUsed
+		}
+		items = append(items, item)
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	fmt.Println(string(data))
+}
+
+// printPlugins prints the plugins on PATH for the help
+func printPlugins(entries []Entry, shortcutMap map[string]int) {
+	plugins := listPlugins()
+	if len(plugins) == 0 || appSettings.pluginPrecedence() == pluginsOff {
+		return
+	}
+	fmt.Printf("\n%s\n", messages.HelpPlugins)
+	for _, name := range plugins {
+		if reason := pluginShadowed(name, entries, shortcutMap); reason != "" {
+			fmt.Printf("  goto %-16s %s\n", name, reason)
+		} else {
+			fmt.Printf("  goto %s\n", name)
+		}
+	}
+}
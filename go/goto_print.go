@@ -86,21 +86,22 @@ func shortenPathMiddle(path string, maxLen int) string {
 
 func PrintHorzontalLine(flag string) {
 	// ターミナル横幅取得
-	termWidth := 80
-	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
-		termWidth = w
-	}
+	termWidth, _ := terminalSize()
 
 	// 横線を表示
 	fmt.Println(strings.Repeat(flag, termWidth))
 }
 
-// PrintWhiteBackgroundLine prints a line with white background
-func PrintWhiteBackgroundLine(text string) {
-	// ターミナル横幅取得
-	termWidth := 80
-	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
-		termWidth = w
+// PrintHeaderLine prints a line in the header style of the theme
+func PrintHeaderLine(text string) {
+	termWidth, _ := terminalSize()
+	fmt.Print(headerLine(text, termWidth))
+}
+
+// headerLine returns text in the header style of the theme, padded to the width
+func headerLine(text string, termWidth int) string {
+	if !theme.Enabled {
+		return text
 	}
 
 	// テキストの表示幅を計算
@@ -112,28 +113,29 @@ func PrintWhiteBackgroundLine(text string) {
 		paddingWidth = 0
 	}
 
-	// 一行全部を白背景にして表示
-	BOW := "\033[47;30m"
-	EOW := "\033[0m"
-	fmt.Printf(
-		"%s%s%*s%s",
-		BOW,
-		text,
-		paddingWidth, "",
-		EOW,
-	)
+	// 一行全部をヘッダーの色で表示
+	return theme.paint(theme.Header, fmt.Sprintf("%s%*s", text, paddingWidth, ""))
+}
+
+// terminalSize returns the width and height of the terminal (80x24 when unknown)
+func terminalSize() (int, int) {
+	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return w, h
+	}
+	return 80, 24
 }
 
 // showInteractiveHelp displays help information same as goto -h
-func showInteractiveHelp() {
+func showInteractiveHelp(tomlFile string) {
 	// Clear screen and show help
 	fmt.Print("\033[2J\033[H")
 
 	// Call the same help function as goto -h
-	showHelp()
+	showHelp(tomlFile, activeHistoryFile)
+	printKeyBindings()
 
 	fmt.Println(strings.Repeat("=", 50))
-	fmt.Println("Press any key to continue...")
+	fmt.Println(messages.PressAnyKey)
 
 	// Wait for key press
 	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
@@ -0,0 +1,216 @@
+// goto_profile.go - Named profiles
+// This file contains profiles, each bundling a configuration file and a
+// history file, e.g. for work and personal bookmarks. The profile is taken
+// from --profile, then GOTO_PROFILE, then the one saved by "goto profile use",
+// and is "default" otherwise. --config-file and --history-file still override
+// the files of the profile; see goto_paths.go for where the files live.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	profileEnvVar  = "GOTO_PROFILE"
+	defaultProfile = "default"
+	profileCommand = "profile"
+)
+
+// profileSubcommands lists the subcommands of "goto profile"
+var profileSubcommands = []string{"list", "use", "new"}
+
+// profileNamePattern matches valid profile names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// activeProfile is the profile of this run, shown in the menu header
+var activeProfile = defaultProfile
+
+// activeHistoryFile is the history file of this run, shown in the help
+var activeHistoryFile string
+
+// profileConfigPath returns the configuration file of the profile
+// ("default" -> config.toml, "work" -> profiles/work.toml)
+func profileConfigPath(name string) string {
+	return preferExisting(xdgConfigPath(name), legacyConfigPath(name))
+}
+
+// profileHistoryPath returns the history file of the profile
+// ("default" -> history.json, "work" -> profiles/work.history.json)
+func profileHistoryPath(name string) string {
+	return preferExisting(xdgHistoryPath(name), legacyHistoryPath(name))
+}
+
+// xdgSavedProfilePath returns the file remembering the profile chosen with "goto profile use"
+func xdgSavedProfilePath() string {
+	return filepath.Join(stateDir(), "profile")
+}
+
+// legacySavedProfilePath returns the file of earlier versions remembering the profile
+func legacySavedProfilePath() string {
+	return filepath.Join(homeDir(), ".goto.profile")
+}
+
+// savedProfilePath returns the file remembering the profile chosen with "goto profile use"
+func savedProfilePath() string {
+	return preferExisting(xdgSavedProfilePath(), legacySavedProfilePath())
+}
+
+// selectedProfile returns the profile from the flag, the environment or the saved choice
+func selectedProfile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if name := os.Getenv(profileEnvVar); name != "" {
+		return name
+	}
+	if data, err := os.ReadFile(savedProfilePath()); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	return defaultProfile
+}
+
+// profileExists reports whether the configuration file of the profile exists
+func profileExists(name string) bool {
+	return name == defaultProfile || FileExists(profileConfigPath(name))
+}
+
+// listProfiles returns the names of all profiles, "default" first
+func listProfiles() []string {
+	found := make(map[string]bool)
+	xdgMatches, _ := filepath.Glob(filepath.Join(configDir(), "profiles", "*.toml"))
+	legacyMatches, _ := filepath.Glob(filepath.Join(homeDir(), ".goto.*.toml"))
+	for _, match := range append(xdgMatches, legacyMatches...) {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), ".goto."), ".toml")
+		if profileNamePattern.MatchString(name) && name != defaultProfile {
+			found[name] = true
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{defaultProfile}, names...)
+}
+
+// resolveProfileFiles fills in the configuration and history files of the
+// selected profile unless they were given explicitly. It is the only place
+// the default files are chosen, so every code path uses the same pair.
+func resolveProfileFiles(config *AppConfig) {
+	// GOTO_CONFIG and GOTO_HISTORY stand in for the flags unless a profile is given
+	if config.ConfigFile == "" && config.Profile == "" {
+		config.ConfigFile = os.Getenv(configEnvVar)
+	}
+	if config.HistoryFile == "" && config.Profile == "" {
+		config.HistoryFile = os.Getenv(historyEnvVar)
+	}
+
+	name := selectedProfile(config.Profile)
+	if !profileNamePattern.MatchString(name) {
+		fmt.Fprintf(os.Stderr, messages.ErrorInvalidProfileName+"\n", name)
+		os.Exit(1)
+	}
+	if config.ConfigFile == "" && !profileExists(name) {
+		fmt.Fprintf(os.Stderr, messages.ErrorUnknownProfile+"\n", name, strings.Join(listProfiles(), ", "))
+		fmt.Fprintf(os.Stderr, messages.ProfileNewHint+"\n", name)
+		os.Exit(1)
+	}
+
+	activeProfile = name
+	if config.ConfigFile == "" {
+		config.ConfigFile = profileConfigPath(name)
+	}
+	if config.HistoryFile == "" {
+		config.HistoryFile = profileHistoryPath(name)
+	}
+	activeHistoryFile = config.HistoryFile
+}
+
+// menuTitle returns the menu header, naming the profile unless it is the
+// default one and the --tag filter if any
+func menuTitle() string {
+	title := messages.AvailableDestinations
+	if activeProfile != defaultProfile {
+		title += " " + fmt.Sprintf(messages.ProfileIndicator, activeProfile)
+	}
+	if activeTagFilter != "" {
+		title += " " + fmt.Sprintf(messages.TagIndicator, activeTagFilter)
+	}
+	return title
+}
+
+// runProfileCommand runs "goto profile list|use NAME|new NAME" and exits
+func runProfileCommand(args []string, appConfig AppConfig) {
+	subcommand, name := "list", ""
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	if len(args) > 1 {
+		name = args[1]
+	}
+
+	switch subcommand {
+	case "list":
+		current := selectedProfile(appConfig.Profile)
+		fmt.Println(messages.ProfileList)
+		for _, profile := range listProfiles() {
+			marker := "  "
+			if profile == current {
+				marker = "* "
+			}
+			fmt.Printf("%s%s → %s\n", marker, profile, displayPath(profileConfigPath(profile)))
+		}
+	case "use":
+		if !profileNamePattern.MatchString(name) {
+			fmt.Printf(messages.ErrorInvalidProfileName+"\n", name)
+			os.Exit(1)
+		}
+		if !profileExists(name) {
+			fmt.Printf(messages.ErrorUnknownProfile+"\n", name, strings.Join(listProfiles(), ", "))
+			fmt.Printf(messages.ProfileNewHint+"\n", name)
+			os.Exit(1)
+		}
+		var err error
+		if name == defaultProfile {
+			err = os.Remove(savedProfilePath())
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			if err = ensureParentDir(savedProfilePath()); err == nil {
+				err = os.WriteFile(savedProfilePath(), []byte(name+"\n"), 0644)
+			}
+		}
+		if err != nil {
+			fmt.Printf("%s %v\n", messages.ErrorWritingConfigFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf(messages.ProfileSwitched+"\n", name)
+		if env := os.Getenv(profileEnvVar); env != "" && env != name {
+			fmt.Printf(messages.ProfileOverriddenByEnv+"\n", profileEnvVar, env)
+		}
+	case "new":
+		if !profileNamePattern.MatchString(name) || name == defaultProfile {
+			fmt.Printf(messages.ErrorInvalidProfileName+"\n", name)
+			os.Exit(1)
+		}
+		if profileExists(name) {
+			fmt.Printf(messages.ErrorProfileExists+"\n", name)
+			os.Exit(1)
+		}
+		createDefaultConfig(profileConfigPath(name))
+		fmt.Printf(messages.ProfileCreated+"\n", name, name)
+	default:
+		fmt.Println(messages.ProfileUsage)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,273 @@
+// goto_provider.go - Provider entries
+// This file contains entries whose destinations are printed by a command,
+// e.g. the services of a manifest or the worktrees of a repository:
+//
+//	[worktrees]
+//	provider = "git -C ~/src/app worktree list --porcelain | my-filter"
+//	ttl = "10m"
+//	timeout = "2s"
+//
+// The command runs with /bin/sh in the directory of the configuration file
+// and prints either JSON, an array of objects (or {"entries": [...]}) with
+// label, path, shortcut, command, description and tags:
+//
+//	[{"label": "api", "path": "~/src/app/api", "shortcut": "a"}]
+//
+// or one destination per line with tab-separated fields:
+//
+//	label<TAB>path[<TAB>shortcut[<TAB>command]]
+//
+// Blank lines and lines starting with # are ignored. Results are cached for
+// ttl (default 1m). A provider that fails or times out (default 3s) is
+// reported and its last results are used, so it never breaks the menu.
+// Providers of .goto.toml files only run once trusted with "goto trust".
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProviderTTL     = time.Minute
+	defaultProviderTimeout = 3 * time.Second
+)
+
+// providedEntry is a destination printed by a provider
+type providedEntry struct {
+	Label       string   `json:"label"`
+	Path        string   `json:"path"`
+	Shortcut    string   `json:"shortcut,omitempty"`
+	Command     string   `json:"command,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// providerCache represents the cache file: provider key -> results
+type providerCache struct {
+	Entries map[string]cachedProvider `json:"entries"`
+}
+
+// cachedProvider is the output of a provider at a time
+type cachedProvider struct {
+	Time    time.Time       `json:"time"`
+	Entries []providedEntry `json:"entries"`
+}
+
+// validateProvider checks the fields of a provider entry
+func validateProvider(dest Destination) error {
+	switch {
+	case dest.Path != "" || dest.isGenerator():
+		return fmt.Errorf("provider cannot be combined with path, glob or scan")
+	case dest.Command != "" || dest.Shortcut != "":
+		return fmt.Errorf("commands and shortcuts of a provider come from its output")
+	}
+	for field, value := range map[string]string{"ttl": dest.TTL, "timeout": dest.Timeout} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// providerDuration parses a duration field, falling back to the default
+func providerDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	return fallback
+}
+
+// expandProviders replaces the provider entries of the configuration with
+// the destinations they print. Failures are reported as warnings; only an
+// invalid provider entry is an error. Providers of local files run only when
+// their command is trusted.
+func expandProviders(config map[string]Destination, tomlFile string, local bool) error {
+	names := make([]string, 0)
+	for name, dest := range config {
+		if dest.Provider != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	provided := make(map[string]Destination)
+	for _, name := range names {
+		provider := config[name]
+		delete(config, name)
+		if err := validateProvider(provider); err != nil {
+			return fmt.Errorf("[%s] %w", name, err)
+		}
+		if local && !isTrusted(tomlFile, name, provider.Provider) {
+			fmt.Fprintf(os.Stderr, messages.WarningProviderUntrusted+"\n", name, tomlFile)
+			continue
+		}
+
+		entries, err := providerEntries(provider, filepath.Dir(tomlFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, messages.WarningProviderFailed+" %v\n", name, err)
+		}
+		for _, entry := range entries {
+			if _, exists := provided[entry.Label]; exists {
+				continue
+			}
+			tags := entry.Tags
+			if len(tags) == 0 {
+				tags = provider.Tags
+			}
+			provided[entry.Label] = Destination{
+				Path:        entry.Path,
+				Shortcut:    entry.Shortcut,
+				Command:     entry.Command,
+				Description: entry.Description,
+				Tags:        tags,
+				Pinned:      provider.Pinned,
+				Order:       provider.Order,
+			}
+		}
+	}
+
+	for label, dest := range provided {
+		if _, exists := config[label]; !exists {
+			config[label] = dest
+		}
+	}
+	return nil
+}
+
+// providerEntries returns the destinations of a provider, from the cache
+// while it is fresh. When the command fails, the last results are returned
+// with the error.
+func providerEntries(provider Destination, dir string) ([]providedEntry, error) {
+	key := dir + "\x00" + provider.Provider
+	cache := loadProviderCache()
+	cached, ok := cache.Entries[key]
+	if cachedExpansionsOnly || ok && (preferCachedExpansions || time.Since(cached.Time) < providerDuration(provider.TTL, defaultProviderTTL)) {
+		return cached.Entries, nil
+	}
+
+	entries, err := runProvider(provider.Provider, dir, providerDuration(provider.Timeout, defaultProviderTimeout))
+	if err != nil {
+		return cached.Entries, err
+	}
+	cache.Entries[key] = cachedProvider{Time: time.Now(), Entries: entries}
+	saveProviderCache(cache)
+	return entries, nil
+}
+
+// runProvider runs a provider command and parses its output
+func runProvider(command, dir string, timeout time.Duration) ([]providedEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Dir = dir
+	cmd.WaitDelay = 100 * time.Millisecond // Children left running must not hold the output open
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+
+	entries, err := parseProviderOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Path = resolveLocalPath(dir, entries[i].Path)
+	}
+	return entries, nil
+}
+
+// parseProviderOutput parses the JSON or line protocol
+func parseProviderOutput(output []byte) ([]providedEntry, error) {
+	var entries []providedEntry
+	trimmed := bytes.TrimSpace(output)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var wrapped struct {
+			Entries []providedEntry `json:"entries"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapped); err != nil {
+			return nil, err
+		}
+		entries = wrapped.Entries
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for lineNumber := 1; scanner.Scan(); lineNumber++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Split(line, "\t")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: expected label<TAB>path", lineNumber)
+			}
+			entry := providedEntry{Label: fields[0], Path: fields[1]}
+			if len(fields) > 2 {
+				entry.Shortcut = fields[2]
+			}
+			if len(fields) > 3 {
+				entry.Command = fields[3]
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	for i, entry := range entries {
+		if entry.Label == "" || entry.Path == "" {
+			return nil, fmt.Errorf("entry %d: label and path are required", i+1)
+		}
+	}
+	return entries, nil
+}
+
+// providerCachePath returns the cache file of the providers
+func providerCachePath() string {
+	return filepath.Join(cacheDir(), "providers.json")
+}
+
+// loadProviderCache loads the cache; a missing or damaged file is empty
+func loadProviderCache() providerCache {
+	cache := providerCache{Entries: make(map[string]cachedProvider)}
+	if data, err := os.ReadFile(providerCachePath()); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]cachedProvider)
+	}
+	return cache
+}
+
+// saveProviderCache saves the cache; failures only cost running the provider again
+func saveProviderCache(cache providerCache) {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil || ensureParentDir(providerCachePath()) != nil {
+		return
+	}
+	os.WriteFile(providerCachePath(), data, 0644)
+}
@@ -0,0 +1,151 @@
+// goto_sort.go - Order of the destinations
+// This file contains the order of the menu, --list and the positional
+// numbers, set by the sort setting or --sort:
+//
+//	history       most recently used first (default)
+//	frecency      most often and recently used first
+//	alphabetical  by label
+//	config        in the order of the configuration file
+//
+// Entries with pinned = true always come first, in configuration order, so
+// their numbers do not change. Destinations without history follow the used
+// ones alphabetically in the history and frecency orders.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Values of the sort setting
+const (
+	sortHistory      = "history"
+	sortFrecency     = "frecency"
+	sortAlphabetical = "alphabetical"
+	sortConfig       = "config"
+)
+
+// sortModes lists the values of the sort setting
+var sortModes = []string{sortHistory, sortFrecency, sortAlphabetical, sortConfig}
+
+// sortOverride holds the --sort value, which wins over the sort setting
+var sortOverride string
+
+// validSortMode reports whether the value is a known sort order
+func validSortMode(value string) bool {
+	for _, mode := range sortModes {
+		if value == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// sortMode returns the order of the entries: --sort, the sort setting or history
+func (g GeneralSettings) sortMode() string {
+	switch {
+	case sortOverride != "":
+		return sortOverride
+	case g.Sort != "":
+		return g.Sort
+	}
+	return sortHistory
+}
+
+// applySortOption validates --sort and makes it override the sort setting
+func applySortOption(appConfig AppConfig) {
+	if appConfig.Sort == "" {
+		return
+	}
+	if !validSortMode(appConfig.Sort) {
+		if appConfig.Complete {
+			return
+		}
+		fmt.Fprintf(os.Stderr, messages.ErrorUnknownSort+"\n", appConfig.Sort, strings.Join(sortModes, ", "))
+		os.Exit(1)
+	}
+	sortOverride = appConfig.Sort
+}
+
+// sortEntries sorts the entries in the mode, with pinned entries first
+func sortEntries(entries []Entry, mode string) {
+	now := time.Now()
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if a.Pinned {
+			return configLess(a, b)
+		}
+
+		switch mode {
+		case sortAlphabetical:
+			return alphabeticalLess(a, b)
+		case sortConfig:
+			return configLess(a, b)
+		case sortFrecency:
+			if sa, sb := frecencyScore(a, now), frecencyScore(b, now); sa != sb {
+				return sa > sb
+			}
+		}
+		return historyLess(a, b)
+	})
+}
+
+// historyLess orders used entries by their last use, then the others by label
+func historyLess(a, b Entry) bool {
+	if a.LastUsed.IsZero() != b.LastUsed.IsZero() {
+		return !a.LastUsed.IsZero()
+	}
+	if !a.LastUsed.Equal(b.LastUsed) {
+		return a.LastUsed.After(b.LastUsed)
+	}
+	return a.Label < b.Label
+}
+
+// alphabeticalLess orders entries by label, ignoring case
+func alphabeticalLess(a, b Entry) bool {
+	if la, lb := strings.ToLower(a.Label), strings.ToLower(b.Label); la != lb {
+		return la < lb
+	}
+	return a.Label < b.Label
+}
+
+// configLess orders entries as they appear in the configuration files;
+// destinations generated by one entry are ordered by label
+func configLess(a, b Entry) bool {
+	if a.Order != b.Order {
+		return a.Order < b.Order
+	}
+	return a.Label < b.Label
+}
+
+// frecencyScore weighs the visits of an entry by how recently it was used
+func frecencyScore(entry Entry, now time.Time) float64 {
+	if entry.LastUsed.IsZero() {
+		return 0
+	}
+	visits := entry.Visits
+	if visits == 0 {
+		visits = 1 // History written before visits were counted
+	}
+
+	age := now.Sub(entry.LastUsed)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	case age < 30*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(visits) * weight
+}
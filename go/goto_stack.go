@@ -0,0 +1,272 @@
+// goto_stack.go - Per-session navigation stack
+// This file contains the directory stack behind "goto -", "goto --back N"
+// and "goto stack". Before goto opens a shell in a destination, the directory
+// it was started from is pushed onto the stack of the session; going back
+// pops it again.
+//
+// A session is identified by the GOTO_SESSION environment variable. goto
+// sets it in every shell it opens, so nested subshells share one stack; a
+// shell integration wrapper may also export it, e.g. GOTO_SESSION=$$.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	sessionEnvVar    = "GOTO_SESSION"
+	maxStackEntries  = 50                  // Entries kept per session
+	stackSessionTTL  = 30 * 24 * time.Hour // Sessions unused for longer are dropped
+	backCommand      = "-"
+	backStepsCommand = "--back"
+	stackCommand     = "stack"
+)
+
+// StackEntry is a directory goto was started from
+type StackEntry struct {
+	Label   string    `json:"label,omitempty"`   // Destination containing the directory
+	Subpath string    `json:"subpath,omitempty"` // Directory relative to the destination
+	Path    string    `json:"path"`
+	Time    time.Time `json:"time"`
+}
+
+// SessionStack is the navigation stack of a session
+type SessionStack struct {
+	Entries []StackEntry `json:"entries"` // Oldest first
+	Updated time.Time    `json:"updated"`
+}
+
+// NavigationStacks represents the stack file
+type NavigationStacks struct {
+	Sessions map[string]*SessionStack `json:"sessions"`
+}
+
+// currentSession is the session of this process, created on first use
+var currentSession string
+
+// sessionID returns the session from GOTO_SESSION or creates a new one
+func sessionID() string {
+	if currentSession == "" {
+		currentSession = os.Getenv(sessionEnvVar)
+	}
+	if currentSession == "" {
+		currentSession = fmt.Sprintf("%d-%x", os.Getpid(), time.Now().UnixNano())
+	}
+	return currentSession
+}
+
+// getStackFilePath returns the stack file, stored next to the history file
+// ("history.json" -> "history.stack.json")
+func getStackFilePath(customHistoryFile string) (string, error) {
+	historyFile := customHistoryFile
+	if historyFile == "" {
+		var err error
+		if historyFile, err = getHistoryFilePath(); err != nil {
+			return "", err
+		}
+	}
+	return stackPathOf(historyFile), nil
+}
+
+// loadStacks loads the stack file; a missing file is an empty one
+func loadStacks(stackFile string) (NavigationStacks, error) {
+	stacks := NavigationStacks{Sessions: make(map[string]*SessionStack)}
+	data, err := os.ReadFile(stackFile)
+	if os.IsNotExist(err) {
+		return stacks, nil
+	}
+	if err != nil {
+		return stacks, err
+	}
+	if err := json.Unmarshal(data, &stacks); err != nil {
+		return stacks, err
+	}
+	if stacks.Sessions == nil {
+		stacks.Sessions = make(map[string]*SessionStack)
+	}
+	return stacks, nil
+}
+
+// saveStacks saves the stack file, dropping sessions that were not used recently
+func saveStacks(stackFile string, stacks NavigationStacks) error {
+	for id, stack := range stacks.Sessions {
+		if time.Since(stack.Updated) > stackSessionTTL || len(stack.Entries) == 0 {
+			delete(stacks.Sessions, id)
+		}
+	}
+	data, err := json.MarshalIndent(stacks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ensureParentDir(stackFile); err != nil {
+		return err
+	}
+	return os.WriteFile(stackFile, data, 0644)
+}
+
+// loadSessionStack returns the entries of the current session, oldest first
+func loadSessionStack(customHistoryFile string) ([]StackEntry, error) {
+	stackFile, err := getStackFilePath(customHistoryFile)
+	if err != nil {
+		return nil, err
+	}
+	stacks, err := loadStacks(stackFile)
+	if err != nil {
+		return nil, err
+	}
+	if stack, ok := stacks.Sessions[sessionID()]; ok {
+		return stack.Entries, nil
+	}
+	return nil, nil
+}
+
+// saveSessionStack replaces the entries of the current session
+func saveSessionStack(customHistoryFile string, entries []StackEntry) error {
+	stackFile, err := getStackFilePath(customHistoryFile)
+	if err != nil {
+		return err
+	}
+	stacks, err := loadStacks(stackFile)
+	if err != nil {
+		return err
+	}
+	if len(entries) > maxStackEntries {
+		entries = entries[len(entries)-maxStackEntries:]
+	}
+	stacks.Sessions[sessionID()] = &SessionStack{Entries: entries, Updated: time.Now()}
+	return saveStacks(stackFile, stacks)
+}
+
+// pushOrigin pushes the current directory before goto leaves it for targetDir.
+// The directory is recorded with the destination containing it, if any.
+func pushOrigin(entries []Entry, targetDir, customHistoryFile string) {
+	if IsURL(targetDir) {
+		return
+	}
+	dir, err := os.Getwd()
+	if err != nil || canonicalPath(dir) == canonicalPath(targetDir) {
+		return
+	}
+
+	origin := StackEntry{Path: canonicalPath(dir), Time: time.Now()}
+	if result, ok := findContainingDestination(entries, dir); ok {
+		origin.Label, origin.Subpath = result.Label, result.Subpath
+	}
+
+	stack, err := loadSessionStack(customHistoryFile)
+	if err == nil {
+		err = saveSessionStack(customHistoryFile, append(stack, origin))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningFailedToUpdateStack, err)
+	}
+}
+
+// stackTarget returns the directory of a stack entry. A labelled entry is
+// resolved through the current configuration, so it follows a moved
+// destination; otherwise the recorded path is used.
+func stackTarget(item StackEntry, entries []Entry) string {
+	for _, entry := range entries {
+		if entry.Label != item.Label || IsURL(entry.Path) {
+			continue
+		}
+		if dir, err := resolveSubpath(expandPath(entry.Path), item.Subpath); err == nil {
+			return dir
+		}
+	}
+	return item.Path
+}
+
+// stackLabel returns the label shown for a stack entry, e.g. "mono/services"
+func stackLabel(item StackEntry) string {
+	if item.Label != "" && item.Subpath != "" {
+		return item.Label + "/" + item.Subpath
+	}
+	return item.Label
+}
+
+// parseBackSteps returns the number of steps of "goto -" or "goto --back N"
+func parseBackSteps(args []string) (int, error) {
+	if args[0] == backCommand {
+		return 1, nil
+	}
+	if len(args) < 2 {
+		return 0, fmt.Errorf(messages.ErrorInvalidBackSteps, "")
+	}
+	steps, err := strconv.Atoi(args[1])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf(messages.ErrorInvalidBackSteps, args[1])
+	}
+	return steps, nil
+}
+
+// navigateBack pops steps entries from the stack and opens the last one popped
+func navigateBack(args []string, entries []Entry, tomlFile, customHistoryFile string) {
+	steps, err := parseBackSteps(args)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	stack, err := loadSessionStack(customHistoryFile)
+	if err != nil {
+		fmt.Printf("%s %v\n", messages.WarningFailedToUpdateStack, err)
+		os.Exit(1)
+	}
+	if len(stack) == 0 {
+		fmt.Println(messages.StackEmpty)
+		os.Exit(1)
+	}
+	if steps > len(stack) {
+		fmt.Printf(messages.ErrorBackTooFar+"\n", steps, len(stack))
+		os.Exit(1)
+	}
+
+	item := stack[len(stack)-steps]
+	if err := saveSessionStack(customHistoryFile, stack[:len(stack)-steps]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningFailedToUpdateStack, err)
+	}
+
+	// Going back opens a plain shell; the command of the destination is not run again
+	label := stackLabel(item)
+	if label != "" {
+		fmt.Printf("%s %s\n", messages.FoundDestination, label)
+	}
+	if item.Label != "" {
+		if err := UpdateSubpathHistory(tomlFile, item.Label, item.Subpath, customHistoryFile); err != nil {
+			fmt.Printf("%s %v\n", messages.WarningFailedToUpdateHistory, err)
+		}
+	}
+	if !openNewShell(stackTarget(item, entries), "", label) {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// showStack lists the stack of the current session, most recent first
+func showStack(customHistoryFile string) {
+	stack, err := loadSessionStack(customHistoryFile)
+	if err != nil {
+		fmt.Printf("%s %v\n", messages.WarningFailedToUpdateStack, err)
+		os.Exit(1)
+	}
+	if len(stack) == 0 {
+		fmt.Println(messages.StackEmpty)
+		return
+	}
+
+	fmt.Println(messages.NavigationStack)
+	for i := len(stack) - 1; i >= 0; i-- {
+		item := stack[i]
+		if label := stackLabel(item); label != "" {
+			fmt.Printf("%d. %s → %s\n", len(stack)-i, label, item.Path)
+		} else {
+			fmt.Printf("%d. %s\n", len(stack)-i, item.Path)
+		}
+	}
+}
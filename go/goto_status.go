@@ -0,0 +1,216 @@
+// goto_status.go - Status indicators for destinations
+// This file contains the checks that classify each destination as a
+// directory, URL, missing path or unreadable directory, and optionally
+// inspect git repositories. Checks run concurrently within a time budget,
+// so a slow network mount cannot stall the menu.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ColumnStatus is the column showing the status indicator of a destination
+const ColumnStatus = "status"
+
+// Defaults for the status checks
+const (
+	defaultStatusTimeout = 300 * time.Millisecond
+	maxStatusWorkers     = 8
+)
+
+// statusKind classifies a destination
+type statusKind int
+
+const (
+	statusUnchecked  statusKind = iota // Checks are disabled or not run yet
+	statusDirectory                    // An existing, readable directory
+	statusURL                          // A URL opened in the browser
+	statusMissing                      // The path does not exist or is not a directory
+	statusUnreadable                   // The directory cannot be read
+	statusTimeout                      // The check did not finish within the time budget
+)
+
+// EntryStatus describes the type and health of a destination
+type EntryStatus struct {
+	Kind statusKind
+	Git  *GitStatus // nil unless the directory is a git repository and git checks are enabled
+}
+
+// GitStatus describes the state of a git working tree
+type GitStatus struct {
+	Dirty  bool
+	Ahead  int
+	Behind int
+}
+
+// statusIcons contains the indicator shown for each status kind
+var statusIcons = map[statusKind]string{
+	statusDirectory:  "📁",
+	statusURL:        "🌐",
+	statusMissing:    "❌",
+	statusUnreadable: "🔒",
+	statusTimeout:    "⏳",
+}
+
+// indicator returns the text shown in the status column
+func (s EntryStatus) indicator() string {
+	icon := statusIcons[s.Kind]
+	if s.Git == nil {
+		return icon
+	}
+	if s.Git.Dirty {
+		icon += "*"
+	}
+	if s.Git.Ahead > 0 {
+		icon += "↑" + strconv.Itoa(s.Git.Ahead)
+	}
+	if s.Git.Behind > 0 {
+		icon += "↓" + strconv.Itoa(s.Git.Behind)
+	}
+	return icon
+}
+
+// annotateStatuses fills in the status of each entry when status checks are enabled
+func annotateStatuses(entries []Entry) {
+	if !appSettings.statusEnabled() {
+		return
+	}
+	statuses := checkStatuses(entries, appSettings.statusTimeout(), appSettings.GitStatus)
+	for i := range entries {
+		entries[i].Status = statuses[i]
+	}
+}
+
+// annotateOutputStatuses fills in the statuses of a table printed to stdout.
+// Like colours, the indicators are left out when stdout is not a terminal,
+// e.g. "goto --list | grep api", unless status = true is set explicitly.
+func annotateOutputStatuses(entries []Entry) {
+	if appSettings.Status == nil && !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	annotateStatuses(entries)
+}
+
+// checkStatuses checks all entries concurrently. Entries whose check does
+// not finish within budget are reported as statusTimeout.
+func checkStatuses(entries []Entry, budget time.Duration, checkGit bool) []EntryStatus {
+	statuses := make([]EntryStatus, len(entries))
+	for i := range statuses {
+		statuses[i].Kind = statusTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	type result struct {
+		index  int
+		status EntryStatus
+	}
+	results := make(chan result, len(entries))
+	workers := make(chan struct{}, maxStatusWorkers)
+
+	for i, entry := range entries {
+		go func(index int, path string) {
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			results <- result{index, checkStatus(ctx, path, checkGit)}
+		}(i, expandPath(entry.Path))
+	}
+
+	for remaining := len(entries); remaining > 0; remaining-- {
+		select {
+		case r := <-results:
+			statuses[r.index] = r.status
+		case <-ctx.Done():
+			return statuses
+		}
+	}
+	return statuses
+}
+
+// checkStatus checks a single destination path
+func checkStatus(ctx context.Context, path string, checkGit bool) EntryStatus {
+	if IsURL(path) {
+		return EntryStatus{Kind: statusURL}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsPermission(err) {
+			return EntryStatus{Kind: statusUnreadable}
+		}
+		return EntryStatus{Kind: statusMissing}
+	}
+	if !info.IsDir() {
+		return EntryStatus{Kind: statusMissing}
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return EntryStatus{Kind: statusUnreadable}
+	}
+	_, err = dir.Readdirnames(1)
+	dir.Close()
+	if err != nil && err != io.EOF {
+		return EntryStatus{Kind: statusUnreadable}
+	}
+
+	status := EntryStatus{Kind: statusDirectory}
+	if checkGit && FileExists(filepath.Join(path, ".git")) {
+		status.Git = checkGitStatus(ctx, path)
+	}
+	return status
+}
+
+// checkGitStatus returns the working tree state of a git repository, or nil on failure
+func checkGitStatus(ctx context.Context, path string) *GitStatus {
+	cmd := exec.CommandContext(ctx, "git", "-C", path, "status", "--porcelain=v1", "--branch")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseGitStatus(out)
+}
+
+// parseGitStatus parses the output of "git status --porcelain=v1 --branch"
+func parseGitStatus(out []byte) *GitStatus {
+	status := &GitStatus{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "## ") {
+			if line != "" {
+				status.Dirty = true
+			}
+			continue
+		}
+
+		// e.g. "## main...origin/main [ahead 1, behind 2]"
+		start := strings.LastIndex(line, "[")
+		if start < 0 || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		for _, part := range strings.Split(line[start+1:len(line)-1], ", ") {
+			var n int
+			if _, err := fmt.Sscanf(part, "ahead %d", &n); err == nil {
+				status.Ahead = n
+			} else if _, err := fmt.Sscanf(part, "behind %d", &n); err == nil {
+				status.Behind = n
+			}
+		}
+	}
+	return status
+}
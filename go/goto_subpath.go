@@ -0,0 +1,140 @@
+// goto_subpath.go - Navigation beneath a destination
+// This file contains the resolution of arguments like "mono/services/billing",
+// where the first segment selects a destination and the rest is a directory
+// below it, and the completion of such subpaths.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// splitSubpath splits "label/sub/dir" into the destination argument and the
+// subpath. ok is false when the argument has no subpath.
+func splitSubpath(arg string) (head, subpath string, ok bool) {
+	head, subpath, ok = strings.Cut(arg, "/")
+	if !ok || head == "" {
+		return arg, "", false
+	}
+	return head, subpath, true
+}
+
+// resolveSubpath joins the subpath to the expanded destination directory.
+// The subpath must be relative, stay inside the destination and name an
+// existing directory.
+func resolveSubpath(baseDir, subpath string) (string, error) {
+	if IsURL(baseDir) {
+		return "", fmt.Errorf(messages.ErrorSubpathOfURL, baseDir)
+	}
+	if filepath.IsAbs(subpath) {
+		return "", fmt.Errorf(messages.ErrorSubpathOutside, subpath, baseDir)
+	}
+
+	targetDir := filepath.Join(baseDir, subpath)
+	if _, ok := relativeInside(baseDir, targetDir); !ok {
+		return "", fmt.Errorf(messages.ErrorSubpathOutside, subpath, baseDir)
+	}
+
+	info, err := os.Stat(targetDir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf(messages.ErrorSubpathNotDirectory, targetDir)
+	}
+	return targetDir, nil
+}
+
+// cleanSubpath returns the subpath in the form recorded in the history, or ""
+// when it names the destination itself
+func cleanSubpath(subpath string) string {
+	if subpath = filepath.ToSlash(filepath.Clean(subpath)); subpath == "." {
+		return ""
+	}
+	return subpath
+}
+
+// subpathCompletions returns the subdirectories for a word like
+// "mono/services/b", followed by the counted subpaths of the destination
+func subpathCompletions(entries []Entry, shortcutMap map[string]int, current, historyFile string) []completion {
+	head, subpath, ok := splitSubpath(current)
+	if !ok {
+		return nil
+	}
+	baseDir, _, label := findDestinationByArg(head, entries, shortcutMap)
+	if baseDir == "" || IsURL(baseDir) {
+		return nil
+	}
+
+	var candidates []completion
+	suggested := make(map[string]bool)
+	for _, c := range subpathCounts(historyFile, label) {
+		value := head + "/" + c.Value + "/"
+		if strings.HasPrefix(value, current) && value != current {
+			candidates = append(candidates, completion{value, c.Description})
+			suggested[value] = true
+		}
+	}
+
+	// Complete the last segment among the subdirectories, as cd does
+	parent, prefix := "", subpath
+	if i := strings.LastIndex(subpath, "/"); i >= 0 {
+		parent, prefix = subpath[:i+1], subpath[i+1:]
+	}
+	dir, err := resolveSubpath(baseDir, parent)
+	if err != nil {
+		return candidates
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return candidates
+	}
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			if value := head + "/" + parent + name + "/"; !suggested[value] {
+				candidates = append(candidates, completion{value, ""})
+			}
+		}
+	}
+	return candidates
+}
+
+// subpathCounts returns the counted subpaths of the label, most used first
+func subpathCounts(historyFile, label string) []completion {
+	if !appSettings.SubpathCounts || historyFile == "" {
+		return nil
+	}
+	history, err := loadHistory(historyFile)
+	if err != nil {
+		return nil
+	}
+
+	var counts map[string]int
+	for _, hist := range history.Entries {
+		if hist.Label == label {
+			counts = hist.Subpaths
+			break
+		}
+	}
+	subpaths := make([]string, 0, len(counts))
+	for subpath := range counts {
+		subpaths = append(subpaths, subpath)
+	}
+	sort.Slice(subpaths, func(i, j int) bool {
+		if counts[subpaths[i]] != counts[subpaths[j]] {
+			return counts[subpaths[i]] > counts[subpaths[j]]
+		}
+		return subpaths[i] < subpaths[j]
+	})
+
+	candidates := make([]completion, 0, len(subpaths))
+	for _, subpath := range subpaths {
+		candidates = append(candidates, completion{subpath, fmt.Sprintf(messages.SubpathUseCount, counts[subpath])})
+	}
+	return candidates
+}
@@ -0,0 +1,280 @@
+// goto_tags.go - Tags of destinations
+// This file contains the tag filter given with --tag, the tag cycle and group
+// headers of the cursor menu, and "goto check", which validates the tags,
+// shortcuts and aliases of the configuration.
+//
+// A filter is a comma-separated list of terms that must all match. A term
+// is a tag, "!tag" for destinations without it, or alternatives separated by
+// "|" of which one must match:
+//
+//	goto --tag work              # tagged work
+//	goto --tag work,!archived    # tagged work but not archived
+//	goto --tag go|rust,!archived # tagged go or rust, but not archived
+//
+// Tags are compared case-insensitively.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const checkCommand = "check"
+
+// tagTerm is a tag of a filter clause, possibly negated
+type tagTerm struct {
+	tag    string
+	negate bool
+}
+
+// tagFilter is a parsed tag filter: every clause must have a matching term
+type tagFilter [][]tagTerm
+
+// activeTagFilter holds the --tag filter of the invocation, shown in the menu title
+var activeTagFilter string
+
+// parseTagFilter parses a tag filter such as "work,!archived"
+func parseTagFilter(expr string) (tagFilter, error) {
+	var filter tagFilter
+	for _, clause := range strings.Split(expr, ",") {
+		var terms []tagTerm
+		for _, alternative := range strings.Split(clause, "|") {
+			alternative = strings.TrimSpace(alternative)
+			term := tagTerm{tag: strings.TrimPrefix(alternative, "!")}
+			term.negate = term.tag != alternative
+			if err := validateTag(term.tag); err != nil {
+				return nil, fmt.Errorf("%q: %w", expr, err)
+			}
+			terms = append(terms, term)
+		}
+		filter = append(filter, terms)
+	}
+	return filter, nil
+}
+
+// matches reports whether the tags satisfy the filter
+func (f tagFilter) matches(tags []string) bool {
+	for _, clause := range f {
+		matched := false
+		for _, term := range clause {
+			if hasTag(tags, term.tag) != term.negate {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTag reports whether the tag is among the tags
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTag checks that a tag can be used in a filter
+func validateTag(tag string) error {
+	switch {
+	case tag == "":
+		return fmt.Errorf("%s", messages.TagEmpty)
+	case strings.HasPrefix(tag, "!"):
+		return fmt.Errorf(messages.TagLeadingNegation, tag)
+	case strings.ContainsAny(tag, ",|"):
+		return fmt.Errorf(messages.TagSeparator, tag)
+	case strings.IndexFunc(tag, unicode.IsSpace) >= 0:
+		return fmt.Errorf(messages.TagSpace, tag)
+	}
+	return nil
+}
+
+// filterEntriesByTags returns the entries matching the filter, keeping their order
+func filterEntriesByTags(entries []Entry, filter tagFilter) []Entry {
+	matching := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if filter.matches(entry.Tags) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+// applyTagFilter restricts the entries to the --tag filter of the invocation
+func applyTagFilter(entries []Entry, shortcutMap map[string]int, appConfig AppConfig) ([]Entry, map[string]int) {
+	if appConfig.TagFilter == "" {
+		return entries, shortcutMap
+	}
+	filter, err := parseTagFilter(appConfig.TagFilter)
+	if err != nil {
+		if appConfig.Complete {
+			return entries, shortcutMap
+		}
+		fmt.Printf("%s %v\n", messages.ErrorInvalidTagFilter, err)
+		os.Exit(1)
+	}
+	activeTagFilter = appConfig.TagFilter
+	entries = filterEntriesByTags(entries, filter)
+
+	// An empty --list is a valid answer, e.g. "[]" for plugins
+	listing := len(appConfig.FilteredArgs) > 0 && appConfig.FilteredArgs[0] == "--list"
+	if len(entries) == 0 && !appConfig.Complete && !listing {
+		fmt.Printf(messages.NoDestinationsMatchTags+"\n", appConfig.TagFilter)
+		os.Exit(1)
+	}
+	return entries, buildShortcutMap(entries)
+}
+
+// listTags returns the tags of the entries, sorted case-insensitively
+func listTags(entries []Entry) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			if key := strings.ToLower(tag); !seen[key] && validateTag(tag) == nil {
+				seen[key] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+	return tags
+}
+
+// tagCompletions returns the tags for the term being typed in a --tag value,
+// e.g. "work,!ar" completes to "work,!archived"
+func tagCompletions(entries []Entry, current string) []completion {
+	cut := strings.LastIndexAny(current, ",|") + 1
+	prefix := current[:cut]
+	if strings.HasPrefix(current[cut:], "!") {
+		prefix += "!"
+	}
+	var candidates []completion
+	for _, tag := range listTags(entries) {
+		candidates = append(candidates, completion{Value: prefix + tag})
+	}
+	return candidates
+}
+
+// nextTag returns the tag after current when cycling through the tags; after
+// the last tag the cycle returns to "" (no tag)
+func nextTag(tags []string, current string) string {
+	if current == "" {
+		if len(tags) == 0 {
+			return ""
+		}
+		return tags[0]
+	}
+	for i, tag := range tags {
+		if tag == current && i+1 < len(tags) {
+			return tags[i+1]
+		}
+	}
+	return ""
+}
+
+// tagGroup returns the group of an entry in the menu: its first tag, or ""
+func tagGroup(entry Entry) string {
+	if len(entry.Tags) == 0 {
+		return ""
+	}
+	return entry.Tags[0]
+}
+
+// groupByTag reorders the view so that entries sharing their first tag are
+// adjacent; groups are sorted by tag and untagged entries come last
+func groupByTag(entries []Entry, view []int) {
+	sort.SliceStable(view, func(i, j int) bool {
+		gi, gj := tagGroup(entries[view[i]]), tagGroup(entries[view[j]])
+		if (gi == "") != (gj == "") {
+			return gj == ""
+		}
+		return strings.ToLower(gi) < strings.ToLower(gj)
+	})
+}
+
+// countGroups returns the number of groups in a view ordered by groupByTag
+func countGroups(entries []Entry, view []int) int {
+	count := 0
+	for pos, i := range view {
+		if pos == 0 || tagGroup(entries[i]) != tagGroup(entries[view[pos-1]]) {
+			count++
+		}
+	}
+	return count
+}
+
+// groupHeader returns the header line shown above a group of the menu
+func groupHeader(group string) string {
+	if group == "" {
+		return messages.UntaggedGroup
+	}
+	return theme.paint(theme.Shortcut, "#"+group)
+}
+
+// checkProblems returns the problems of the destinations: invalid tags,
+// shortcuts and aliases used by more than one destination, and local
+// shortcuts dropped for personal ones
+func checkProblems(entries []Entry) []string {
+	var problems []string
+	owners := make(map[string]string)
+	for _, entry := range entries {
+		seen := make(map[string]bool)
+		for _, tag := range entry.Tags {
+			if err := validateTag(tag); err != nil {
+				problems = append(problems, fmt.Sprintf(messages.CheckInvalidTag, entry.Label, err))
+			} else if seen[strings.ToLower(tag)] {
+				problems = append(problems, fmt.Sprintf(messages.CheckDuplicateTag, entry.Label, tag))
+			}
+			seen[strings.ToLower(tag)] = true
+		}
+		if entry.Shortcut == "" {
+			continue
+		}
+		if owner, exists := owners[entry.Shortcut]; exists {
+			problems = append(problems, fmt.Sprintf(messages.CheckDuplicateShortcut, entry.Label, entry.Shortcut, owner))
+		} else {
+			owners[entry.Shortcut] = entry.Label
+		}
+	}
+	problems = append(problems, aliasProblems(entries)...)
+	problems = append(problems, localShortcutProblems()...)
+	sort.Strings(problems)
+	return problems
+}
+
+// runCheck prints the problems of the configuration and exits, with status 1
+// when there are any
+func runCheck(entries []Entry) {
+	// Sorted by label, so that the owner of a duplicate shortcut is stable
+	sorted := append([]Entry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Label < sorted[j].Label
+	})
+
+	// Shadowed shortcuts still work from the command line, so they do not fail the check
+	for _, warning := range validateKeyBindings(keyBindings, sorted) {
+		fmt.Println(warning)
+	}
+	problems := checkProblems(sorted)
+	for _, problem := range problems {
+		fmt.Printf("⚠️  %s\n", problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf(messages.CheckFailed+"\n", len(problems))
+		os.Exit(1)
+	}
+	fmt.Printf(messages.CheckPassed+"\n", len(entries), len(listTags(entries)))
+	os.Exit(0)
+}
@@ -0,0 +1,383 @@
+// goto_theme.go - Colour themes for terminal output
+// This file contains the theme presets, the parser for colour specifications
+// used in the [theme] section, and the detection of colour support
+// (NO_COLOR, TERM=dumb, non-TTY output and the terminal's colour depth).
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ThemeConfig represents the [theme] section of the configuration file
+type ThemeConfig struct {
+	Name      string `toml:"name"`   // Preset name: default, dark, light, mono
+	Color     string `toml:"color"`  // auto, always, never
+	Colors    string `toml:"colors"` // Colour depth: truecolor, 256, 16 (detected when empty)
+	Selection string `toml:"selection"`
+	Header    string `toml:"header"`
+	Label     string `toml:"label"`
+	Shortcut  string `toml:"shortcut"`
+	Path      string `toml:"path"`
+	URL       string `toml:"url"`
+	Missing   string `toml:"missing"`
+}
+
+// Theme holds the ANSI SGR parameters used for each part of the output
+type Theme struct {
+	Enabled   bool
+	Selection string
+	Header    string
+	Label     string
+	Shortcut  string
+	Path      string
+	URL       string
+	Missing   string
+}
+
+// colorDepth is the number of colours supported by the terminal
+type colorDepth int
+
+const (
+	depth16        colorDepth = 16
+	depth256       colorDepth = 256
+	depthTrueColor colorDepth = 1 << 24
+)
+
+// themePresets contains the built-in themes as colour specifications
+var themePresets = map[string]ThemeConfig{
+	"default": {
+		Selection: "black on white",
+		Header:    "black on white",
+		Shortcut:  "cyan",
+		URL:       "blue underline",
+		Missing:   "red",
+	},
+	"dark": {
+		Selection: "bold #000000 on #87d7ff",
+		Header:    "bold #000000 on #87d7ff",
+		Label:     "bold white",
+		Shortcut:  "#ffd75f",
+		Path:      "#a8a8a8",
+		URL:       "#87afff underline",
+		Missing:   "#ff5f5f",
+	},
+	"light": {
+		Selection: "bold #ffffff on #005fd7",
+		Header:    "bold #ffffff on #005fd7",
+		Label:     "bold black",
+		Shortcut:  "#af5f00",
+		Path:      "#585858",
+		URL:       "#0000d7 underline",
+		Missing:   "#d70000",
+	},
+	"mono": {
+		Selection: "reverse",
+		Header:    "reverse",
+		Label:     "bold",
+		Shortcut:  "underline",
+		URL:       "underline",
+		Missing:   "dim",
+	},
+}
+
+// theme holds the active colour theme
+var theme, _ = newTheme(ThemeConfig{})
+
+// namedColors maps colour names to the 16 standard terminal colours
+var namedColors = map[string]int{
+	"black": 0, "red": 1, "green": 2, "yellow": 3,
+	"blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+	"gray": 8, "grey": 8, "bright-black": 8, "bright-red": 9,
+	"bright-green": 10, "bright-yellow": 11, "bright-blue": 12,
+	"bright-magenta": 13, "bright-cyan": 14, "bright-white": 15,
+}
+
+// styleAttributes maps text attribute names to SGR parameters
+var styleAttributes = map[string]string{
+	"bold": "1", "dim": "2", "italic": "3", "underline": "4", "reverse": "7",
+}
+
+// palette16 contains the RGB values of the 16 standard colours (xterm defaults)
+var palette16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels contains the channel values of the 6x6x6 colour cube of 256-colour terminals
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// newTheme builds a theme from the [theme] section
+func newTheme(cfg ThemeConfig) (Theme, error) {
+	name := cfg.Name
+	if name == "" {
+		name = "default"
+	}
+	preset, ok := themePresets[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: default, dark, light, mono)", name)
+	}
+
+	enabled, err := colorEnabled(cfg.Color)
+	if err != nil {
+		return Theme{}, err
+	}
+	depth, err := detectColorDepth(cfg.Colors)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	t := Theme{Enabled: enabled}
+	fields := []struct {
+		name     string
+		preset   string
+		override string
+		target   *string
+	}{
+		{"selection", preset.Selection, cfg.Selection, &t.Selection},
+		{"header", preset.Header, cfg.Header, &t.Header},
+		{"label", preset.Label, cfg.Label, &t.Label},
+		{"shortcut", preset.Shortcut, cfg.Shortcut, &t.Shortcut},
+		{"path", preset.Path, cfg.Path, &t.Path},
+		{"url", preset.URL, cfg.URL, &t.URL},
+		{"missing", preset.Missing, cfg.Missing, &t.Missing},
+	}
+	for _, field := range fields {
+		spec := field.preset
+		if field.override != "" {
+			spec = field.override
+		}
+		sgr, err := parseStyle(spec, depth)
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.target = sgr
+	}
+
+	return t, nil
+}
+
+// colorEnabled decides whether coloured output is used
+func colorEnabled(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		return term.IsTerminal(int(os.Stdout.Fd())), nil
+	}
+	return false, fmt.Errorf("invalid color mode %q (available: auto, always, never)", mode)
+}
+
+// detectColorDepth returns the configured colour depth or detects it from the environment
+func detectColorDepth(value string) (colorDepth, error) {
+	switch strings.ToLower(value) {
+	case "truecolor", "24bit":
+		return depthTrueColor, nil
+	case "256":
+		return depth256, nil
+	case "16":
+		return depth16, nil
+	case "":
+		colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+		if colorTerm == "truecolor" || colorTerm == "24bit" {
+			return depthTrueColor, nil
+		}
+		if strings.Contains(os.Getenv("TERM"), "256color") {
+			return depth256, nil
+		}
+		return depth16, nil
+	}
+	return depth16, fmt.Errorf("invalid colors %q (available: truecolor, 256, 16)", value)
+}
+
+// parseStyle converts a colour specification such as "bold black on #ffd700"
+// to SGR parameters for the given colour depth
+func parseStyle(spec string, depth colorDepth) (string, error) {
+	var codes []string
+	background := false
+
+	for _, token := range strings.Fields(strings.ToLower(spec)) {
+		if token == "on" {
+			background = true
+			continue
+		}
+		if token == "none" || token == "default" {
+			background = false
+			continue
+		}
+		if code, ok := styleAttributes[token]; ok {
+			codes = append(codes, code)
+			continue
+		}
+
+		code, err := colorCode(token, background, depth)
+		if err != nil {
+			return "", err
+		}
+		codes = append(codes, code)
+		background = false
+	}
+
+	if background {
+		return "", fmt.Errorf("missing background colour in %q", spec)
+	}
+	return strings.Join(codes, ";"), nil
+}
+
+// colorCode converts a colour name, 256-colour index or #rrggbb value to SGR parameters
+func colorCode(token string, background bool, depth colorDepth) (string, error) {
+	if index, ok := namedColors[token]; ok {
+		return ansi16Code(index, background), nil
+	}
+
+	if strings.HasPrefix(token, "#") && len(token) == 7 {
+		value, err := strconv.ParseUint(token[1:], 16, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid colour %q", token)
+		}
+		r, g, b := int(value>>16), int(value>>8&0xff), int(value&0xff)
+		switch depth {
+		case depthTrueColor:
+			return fmt.Sprintf("%d;2;%d;%d;%d", extendedBase(background), r, g, b), nil
+		case depth256:
+			return fmt.Sprintf("%d;5;%d", extendedBase(background), rgbTo256(r, g, b)), nil
+		}
+		return ansi16Code(nearest16(r, g, b), background), nil
+	}
+
+	if index, err := strconv.Atoi(token); err == nil && index >= 0 && index <= 255 {
+		if depth >= depth256 {
+			return fmt.Sprintf("%d;5;%d", extendedBase(background), index), nil
+		}
+		if index < 16 {
+			return ansi16Code(index, background), nil
+		}
+		r, g, b := rgbOf256(index)
+		return ansi16Code(nearest16(r, g, b), background), nil
+	}
+
+	return "", fmt.Errorf("invalid colour %q", token)
+}
+
+// extendedBase returns the SGR parameter introducing a 256-colour or RGB colour
+func extendedBase(background bool) int {
+	if background {
+		return 48
+	}
+	return 38
+}
+
+// ansi16Code returns the SGR parameter of one of the 16 standard colours
+func ansi16Code(index int, background bool) string {
+	base := 30
+	if index >= 8 {
+		base = 90
+		index -= 8
+	}
+	if background {
+		base += 10
+	}
+	return strconv.Itoa(base + index)
+}
+
+// rgbTo256 returns the nearest colour of the 256-colour palette (cube or grayscale ramp)
+func rgbTo256(r, g, b int) int {
+	nearestLevel := func(v int) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(level-v) < abs(cubeLevels[best]-v) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	grayIndex := ((r+g+b)/3 - 3) / 10
+	if grayIndex < 0 {
+		grayIndex = 0
+	} else if grayIndex > 23 {
+		grayIndex = 23
+	}
+	grayValue := 8 + 10*grayIndex
+	if colorDistance(r, g, b, grayValue, grayValue, grayValue) < cubeDist {
+		return 232 + grayIndex
+	}
+	return cube
+}
+
+// rgbOf256 returns the RGB value of a 256-colour palette index
+func rgbOf256(index int) (int, int, int) {
+	switch {
+	case index < 16:
+		c := palette16[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		return cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]
+	}
+	gray := 8 + 10*(index-232)
+	return gray, gray, gray
+}
+
+// nearest16 returns the index of the nearest of the 16 standard colours
+func nearest16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range palette16 {
+		dist := colorDistance(r, g, b, c[0], c[1], c[2])
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// colorDistance returns the squared distance between two RGB colours
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// paint wraps text in the SGR style when colours are enabled
+func (t Theme) paint(style, text string) string {
+	if !t.Enabled || style == "" || text == "" {
+		return text
+	}
+	return "\033[" + style + "m" + text + "\033[0m"
+}
+
+// pathStyle returns the style for the path of an entry: URL, missing or plain path
+func (t Theme) pathStyle(entry Entry) string {
+	switch entry.Status.Kind {
+	case statusURL:
+		return t.URL
+	case statusMissing, statusUnreadable:
+		return t.Missing
+	case statusUnchecked:
+		if IsURL(entry.Path) {
+			return t.URL
+		}
+	}
+	return t.Path
+}
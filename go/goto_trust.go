@@ -0,0 +1,239 @@
+// goto_trust.go - Trust for commands of local destinations
+// This file contains a direnv-style trust database for the commands (and
+// provider commands) of destinations from .goto.toml files. It records a hash of each approved
+// command per source file; a command that is new or has changed since (e.g.
+// after a git pull) is shown and only runs once the user approves it again.
+// Commands of the personal configuration are trusted implicitly.
+//
+// "goto trust [PATH]" approves all commands of the .goto.toml files found
+// from PATH (default: the current directory) and "goto untrust [PATH]"
+// forgets them.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	trustCommand   = "trust"
+	untrustCommand = "untrust"
+)
+
+// TrustDB represents the trust database: source file -> label -> command hash
+type TrustDB struct {
+	Sources map[string]map[string]string `json:"sources"`
+}
+
+// trustFilePath returns the trust database in the state directory
+func trustFilePath() string {
+	return filepath.Join(stateDir(), "trust.json")
+}
+
+// commandHash returns the hash recorded for a command
+func commandHash(command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadTrust loads the trust database; a missing file trusts nothing
+func loadTrust() (TrustDB, error) {
+	db := TrustDB{Sources: make(map[string]map[string]string)}
+	data, err := os.ReadFile(trustFilePath())
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return db, err
+	}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return db, err
+	}
+	if db.Sources == nil {
+		db.Sources = make(map[string]map[string]string)
+	}
+	return db, nil
+}
+
+// saveTrust saves the trust database
+func saveTrust(db TrustDB) error {
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ensureParentDir(trustFilePath()); err != nil {
+		return err
+	}
+	return os.WriteFile(trustFilePath(), data, 0600)
+}
+
+// trust records the command of a label in a source file as approved
+func (db TrustDB) trust(source, label, command string) {
+	if db.Sources[source] == nil {
+		db.Sources[source] = make(map[string]string)
+	}
+	db.Sources[source][label] = commandHash(command)
+}
+
+// isTrusted reports whether the trust database has approved text for a label
+// of a source file
+func isTrusted(source, label, text string) bool {
+	db, err := loadTrust()
+	return err == nil && db.Sources[canonicalPath(source)][label] == commandHash(text)
+}
+
+// unscopedLabel returns the label of an entry as written in its file
+func unscopedLabel(entry Entry) string {
+	return strings.TrimPrefix(entry.Label, entry.Scope+":")
+}
+
+// trustedCommand returns the command to run for the destination. The command
+// of a local destination runs when its hash is in the trust database;
+// otherwise it is shown and recorded if the user approves it, and skipped if not.
+func trustedCommand(entries []Entry, label, command string) string {
+	if command == "" {
+		return command
+	}
+	var entry Entry
+	for _, e := range entries {
+		if e.Label == label {
+			entry = e
+			break
+		}
+	}
+	if entry.Source == "" {
+		return command
+	}
+
+	db, err := loadTrust()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningTrustDB, err)
+	}
+	source, name := canonicalPath(entry.Source), unscopedLabel(entry)
+	recorded, known := db.Sources[source][name]
+	if recorded == commandHash(command) {
+		return command
+	}
+
+	if known {
+		fmt.Printf(messages.LocalCommandChanged+"\n", entry.Source)
+	} else {
+		fmt.Printf(messages.LocalCommandPrompt+"\n", entry.Source)
+	}
+	fmt.Printf("    %s\n", command)
+	fmt.Print(messages.LocalCommandConfirm)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		fmt.Println(messages.LocalCommandSkipped)
+		return ""
+	}
+
+	if err == nil {
+		db.trust(source, name, command)
+		err = saveTrust(db)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", messages.WarningTrustDB, err)
+	}
+	return command
+}
+
+// trustTargets returns the .goto.toml files named by the arguments: a file,
+// or the files found from a directory (default: the current directory)
+func trustTargets(tomlFile string, args []string) []string {
+	target := "."
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return []string{canonicalPath(target)}
+	}
+	files := findLocalConfigs(target, tomlFile)
+	for i, file := range files {
+		files[i] = canonicalPath(file)
+	}
+	return files
+}
+
+// runTrustCommand runs "goto trust [PATH]" or "goto untrust [PATH]" and exits
+func runTrustCommand(command string, args []string, tomlFile string) {
+	files := trustTargets(tomlFile, args)
+	if len(files) == 0 {
+		fmt.Println(messages.NoLocalConfigs)
+		os.Exit(1)
+	}
+
+	db, err := loadTrust()
+	if err != nil {
+		fmt.Printf("%s %v\n", messages.WarningTrustDB, err)
+		os.Exit(1)
+	}
+
+	for _, file := range files {
+		if command == untrustCommand {
+			delete(db.Sources, file)
+			fmt.Printf(messages.Untrusted+"\n", file)
+			continue
+		}
+
+		// Providers are trusted first, so the destinations they print can be listed
+		raw, _, err := decodeConfigFile(file)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", messages.WarningLocalConfig, file, err)
+			continue
+		}
+		delete(db.Sources, file) // Commands removed from the file are forgotten
+		for label, dest := range raw {
+			if dest.Provider != "" {
+				db.trust(file, label, dest.Provider)
+			}
+		}
+		if err := saveTrust(db); err != nil {
+			fmt.Printf("%s %v\n", messages.WarningTrustDB, err)
+			os.Exit(1)
+		}
+
+		config, _, err := loadConfigSource(file, true)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", messages.WarningLocalConfig, file, err)
+			continue
+		}
+		trusted := make(map[string]string)
+		for label, dest := range raw {
+			if dest.Provider != "" {
+				trusted[label] = dest.Provider
+			}
+		}
+		for label, dest := range config {
+			if dest.Command != "" {
+				trusted[label] = dest.Command
+			}
+		}
+		labels := make([]string, 0, len(trusted))
+		for label := range trusted {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		fmt.Printf(messages.Trusted+"\n", file, len(labels))
+		for _, label := range labels {
+			db.trust(file, label, trusted[label])
+			fmt.Printf("  %s: %s\n", label, trusted[label])
+		}
+	}
+
+	if err := saveTrust(db); err != nil {
+		fmt.Printf("%s %v\n", messages.WarningTrustDB, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,141 @@
+// goto_which.go - Reverse lookup of destinations
+// This file contains "goto which [--json] [PATH]", which prints the
+// destination containing a directory, e.g. for shell prompts and status lines.
+// It reads only the configuration file: the history is neither loaded nor
+// written, and no default configuration is created.
+//
+// Exit status: 0 when a destination matches, 1 when none does, 2 when the
+// configuration cannot be read.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// jsonOption makes "goto which" and "goto --list" print JSON
+const jsonOption = "--json"
+
+// whichResult is the destination containing a directory
+type whichResult struct {
+	Label    string   `json:"label"`
+	Shortcut string   `json:"shortcut,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Path     string   `json:"path"`              // Canonical path of the destination
+	Subpath  string   `json:"subpath,omitempty"` // Directory relative to the destination
+}
+
+// runWhich prints the destination containing the directory in args (default:
+// the current directory) and exits
+func runWhich(tomlFile string, args []string) {
+	asJSON := false
+	target := ""
+	for _, arg := range args {
+		if arg == jsonOption {
+			asJSON = true
+		} else if target == "" {
+			target = arg
+		}
+	}
+	if target == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", messages.ErrorGettingCurrentDir, err)
+			os.Exit(2)
+		}
+		target = dir
+	}
+
+	// Without a configuration nothing matches
+	if _, err := os.Stat(tomlFile); os.IsNotExist(err) {
+		os.Exit(1)
+	}
+
+	config, settings, err := loadConfigWithSettings(tomlFile)
+	if err == nil {
+		err = applySettings(settings)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", messages.ErrorReadingConfig)
+		fmt.Fprintf(os.Stderr, "📁 %s: %s\n", messages.ConfigFile, tomlFile)
+		fmt.Fprintf(os.Stderr, "🔍 %s: %v\n", messages.ErrorDetails, err)
+		os.Exit(2)
+	}
+	mergeLocalDestinations(config, tomlFile)
+
+	entries := make([]Entry, 0, len(config))
+	for label, dest := range config {
+		entries = append(entries, newEntry(label, dest))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Label < entries[j].Label
+	})
+
+	result, ok := findContainingDestination(entries, target)
+	if !ok {
+		os.Exit(1)
+	}
+
+	if asJSON {
+		data, _ := json.Marshal(result)
+		fmt.Println(string(data))
+	} else if result.Shortcut != "" {
+		fmt.Printf("%s (%s)\n", result.Label, result.Shortcut)
+	} else {
+		fmt.Println(result.Label)
+	}
+	os.Exit(0)
+}
+
+// findContainingDestination returns the destination that is the deepest
+// ancestor of (or equal to) the directory. Paths are compared after
+// expansion and symlink resolution; on equal depth the first entry wins.
+func findContainingDestination(entries []Entry, dir string) (whichResult, bool) {
+	target := canonicalPath(dir)
+
+	var best whichResult
+	found := false
+	for _, entry := range entries {
+		if IsURL(entry.Path) {
+			continue
+		}
+		base := canonicalPath(expandPath(entry.Path))
+		rel, ok := relativeInside(base, target)
+		if !ok || (found && len(base) <= len(best.Path)) {
+			continue
+		}
+		best = whichResult{Label: entry.Label, Shortcut: entry.Shortcut, Tags: entry.Tags, Path: base, Subpath: rel}
+		found = true
+	}
+	return best, found
+}
+
+// canonicalPath returns the absolute path with symlinks resolved. Paths that
+// do not exist are only cleaned.
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// relativeInside returns the path of target relative to base, with "" for
+// base itself. ok is false when target is not inside base.
+func relativeInside(base, target string) (string, bool) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
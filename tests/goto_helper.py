@@ -10,16 +10,22 @@ FILE_GOTO = os.path.join(DIR_ROOT, "go", "goto")
 FILE_CONFIG = "/tmp/goto/goto.toml"
 FILE_HISTORY = "/tmp/goto/history.json"
 
-def run(args, input_text=None):
-    """Run the goto command with given arguments and optional input."""
+def run(args, input_text=None, env=None, cwd=None):
+    """Run the goto command with given arguments, optional input, extra environment variables and working directory."""
     command = [FILE_GOTO] + args
+    run_env = None
+    if env is not None:
+        run_env = dict(os.environ)
+        run_env.update(env)
     # Use with statement for resource management
     with subprocess.Popen(
         command,
         stdout=subprocess.PIPE,
         stderr=subprocess.PIPE,
         stdin=subprocess.PIPE,
-        text=True
+        text=True,
+        env=run_env,
+        cwd=cwd
     ) as process:
         stdout, stderr = process.communicate(input=input_text)
         return process.returncode, stdout, stderr
@@ -61,9 +67,22 @@ path = "/tmp/goto/dir3"
     create_config(FILE_CONFIG, config)
     create_history(FILE_HISTORY, history["entries"])
 
+def default_path(env_var, xdg_var, xdg_default, xdg_name, legacy):
+    """Return the file goto uses by default: $GOTO_*, then XDG, then legacy."""
+    if os.environ.get(env_var):
+        return os.environ[env_var]
+    xdg_home = os.environ.get(xdg_var)
+    if not xdg_home or not os.path.isabs(xdg_home):
+        xdg_home = os.path.expanduser(xdg_default)
+    xdg_path = os.path.join(xdg_home, "goto", xdg_name)
+    legacy_path = os.path.expanduser(legacy)
+    if not os.path.exists(xdg_path) and os.path.exists(legacy_path):
+        return legacy_path
+    return xdg_path
+
 def load_config_org():
     """Load the original configuration."""
-    path_config = os.path.expanduser("~/.goto.toml")
+    path_config = default_path("GOTO_CONFIG", "XDG_CONFIG_HOME", "~/.config", "config.toml", "~/.goto.toml")
     if not os.path.exists(path_config):
         raise FileNotFoundError(f"Configuration file not found: {path_config}")
     with open(path_config, "r", encoding="utf-8") as f:
@@ -71,8 +90,8 @@ def load_config_org():
     return config_content
 
 def load_history_org():
-    """Load the original configuration."""
-    path_config = os.path.expanduser("~/.goto.history.json")
+    """Load the original history."""
+    path_config = default_path("GOTO_HISTORY", "XDG_STATE_HOME", "~/.local/state", "history.json", "~/.goto.history.json")
     if not os.path.exists(path_config):
         raise FileNotFoundError(f"History file not found: {path_config}")
     with open(path_config, "r", encoding="utf-8") as f:
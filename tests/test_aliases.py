@@ -0,0 +1,78 @@
+# test for aliases and multi-key shortcuts
+import goto_helper as helper
+
+FILE_ALIASES_CONFIG = "/tmp/goto/aliases.toml"
+
+ALIASES_CONFIG = """
+[settings]
+status = false
+[backend]
+path = "/tmp/goto/dir1"
+shortcut = "gw"
+aliases = ["api", "server"]
+[gateway]
+path = "/tmp/goto/dir2"
+shortcut = "gx"
+[home]
+path = "/tmp/goto/dir3"
+shortcut = "g"
+"""
+
+def run_aliases(args, env=None):
+    return helper.run([
+        "--config-file", FILE_ALIASES_CONFIG,
+        "--history-file", helper.FILE_HISTORY,
+        "--lang", "en",
+    ] + args, env=env)
+
+def test_alias_navigation():
+    """Test that an alias leads to its destination."""
+    helper.prepare_test()
+    helper.create_config(FILE_ALIASES_CONFIG, ALIASES_CONFIG)
+    ret, out, err = run_aliases(["API"], env={"SHELL": "/bin/true"})
+    assert "Found destination: backend" in out, f"Expected backend but got: {out}"
+
+def test_alias_completion():
+    """Test that aliases are completion candidates."""
+    helper.prepare_test()
+    helper.create_config(FILE_ALIASES_CONFIG, ALIASES_CONFIG)
+    ret, out, err = helper.run(["--complete", "--config-file", FILE_ALIASES_CONFIG, ""])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "server\t→ backend" in out.splitlines(), f"Expected the alias server but got: {out}"
+
+def test_multi_key_shortcut():
+    """Test that cursor mode buffers the keys of longer shortcuts."""
+    helper.prepare_test()
+    helper.create_config(FILE_ALIASES_CONFIG, ALIASES_CONFIG)
+    ret, out, err = run_aliases(["--keys", "g,w"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.strip() == "chosen\tbackend\t/tmp/goto/dir1", f"Expected backend but got: {out.strip()}"
+
+    # A shorter shortcut is chosen with Enter or after the timeout
+    ret, out, err = run_aliases(["--keys", "g,enter"])
+    assert out.strip() == "chosen\thome\t/tmp/goto/dir3", f"Expected home but got: {out.strip()}"
+    ret, out, err = run_aliases(["--keys", "g,wait"])
+    assert out.strip() == "chosen\thome\t/tmp/goto/dir3", f"Expected home but got: {out.strip()}"
+
+def test_multi_key_pending():
+    """Test that the pending keys and candidates are shown."""
+    helper.prepare_test()
+    helper.create_config(FILE_ALIASES_CONFIG, ALIASES_CONFIG)
+    ret, out, err = run_aliases(["--keys", "g,esc", "--snapshots"])
+    frame = out[out.index("--- frame 1: g ---"):out.index("--- frame 2")]
+    assert "g… g gw gx" in frame, f"Expected the pending keys but got: {frame}"
+    frame = out[out.index("--- frame 2"):]
+    assert "g…" not in frame, f"Expected Esc to cancel the keys but got: {frame}"
+
+def test_check_aliases():
+    """Test that goto check reports aliases used twice."""
+    helper.prepare_test()
+    helper.create_config(FILE_ALIASES_CONFIG, ALIASES_CONFIG + """
+[worker]
+path = "/tmp/goto"
+aliases = ["api", "home"]
+""")
+    ret, out, err = run_aliases(["check"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert '[worker] alias "api" is also used by [backend]' in out, f"Expected an alias problem but got: {out}"
+    assert '[worker] alias "home" is the label of another destination' in out, f"Expected an alias problem but got: {out}"
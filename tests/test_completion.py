@@ -0,0 +1,71 @@
+# test for shell completion
+import json
+import os
+import goto_helper as helper
+
+def test_complete_destinations():
+    """Test that completion honors --config-file and describes each label with its path."""
+    helper.prepare_test()
+    ret, out, err = helper.run([
+        "--complete", "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "",
+    ])
+    assert ret == 0, f"Command failed with error: {err}"
+    lines = out.splitlines()
+    assert lines[:3] == [
+        "dir1\t/tmp/goto/dir1",
+        "dir2\t/tmp/goto/dir2",
+        "dir3\t/tmp/goto/dir3",
+    ], f"Expected labels with paths but got: {out.strip()}"
+
+def test_complete_options():
+    """Test that options are completed from the option registry."""
+    ret, out, err = helper.run(["--complete", "--"])
+    assert ret == 0, f"Command failed with error: {err}"
+    values = [line.split("\t")[0] for line in out.splitlines()]
+    for option in ["--config-file", "--history-file", "--lang", "--keys"]:
+        assert option in values, f"Expected {option} but got: {out.strip()}"
+
+def test_completion_scripts():
+    """Test that completion scripts are generated for every supported shell."""
+    for shell in ["bash", "zsh", "fish"]:
+        ret, out, err = helper.run(["completion", shell])
+        assert ret == 0, f"Command failed with error: {err}"
+        assert "--complete" in out, f"Expected the {shell} script but got: {out.strip()}"
+        assert "history-file" in out, f"Expected file options in the {shell} script"
+    ret, out, err = helper.run(["completion", "tcsh"])
+    assert ret != 0, "Expected an unsupported shell to fail"
+
+def test_subpath_navigation():
+    """Test that a path after a label opens a directory beneath the destination."""
+    helper.prepare_test()
+    os.makedirs("/tmp/goto/dir1/sub/deep", exist_ok=True)
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1/sub/deep",
+    ], env={"SHELL": "/bin/true"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "/tmp/goto/dir1/sub/deep" in out, f"Expected the subdirectory but got: {out.strip()}"
+    with open(helper.FILE_HISTORY) as f:
+        labels = [entry["label"] for entry in json.load(f)["entries"]]
+    assert labels == ["dir1", "dir2", "dir3"], f"Expected the parent label to be credited but got: {labels}"
+
+def test_subpath_outside_destination():
+    """Test that a subpath may not leave the destination."""
+    helper.prepare_test()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1/../dir2",
+    ])
+    assert ret != 0, "Expected a subpath outside the destination to fail"
+
+def test_complete_subpath():
+    """Test that subdirectories are completed after the slash."""
+    helper.prepare_test()
+    os.makedirs("/tmp/goto/dir1/sub/deep", exist_ok=True)
+    ret, out, err = helper.run([
+        "--complete", "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1/s",
+    ])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.splitlines() == ["dir1/sub/"], f"Expected the subdirectory but got: {out.strip()}"
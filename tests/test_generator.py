@@ -0,0 +1,70 @@
+# test for glob and scan generator entries
+import os
+import shutil
+import goto_helper as helper
+
+DIR_GEN = "/tmp/goto/gen"
+FILE_GEN_CONFIG = os.path.join(DIR_GEN, "goto.toml")
+ENV = {"XDG_CACHE_HOME": os.path.join(DIR_GEN, "cache")}
+
+def prepare_generators(config):
+    """Create directories to expand and a configuration with generators."""
+    shutil.rmtree(DIR_GEN, ignore_errors=True)
+    for path in ["src/alpha", "src/beta", "src/.hidden", "work/team1/r1/.git", "work/team1/r2", "work/team2/r1/.git"]:
+        os.makedirs(os.path.join(DIR_GEN, path))
+    helper.create_config(FILE_GEN_CONFIG, config)
+
+def list_labels():
+    """Return the labels of the generator configuration."""
+    ret, out, err = helper.run(["--config-file", FILE_GEN_CONFIG, "--history-file", helper.FILE_HISTORY, "--list-label"], env=ENV)
+    assert ret == 0, f"Command failed with error: {err}"
+    return out.split()
+
+def test_glob_generator():
+    """Test that a glob expands into one destination per directory, skipping hidden ones."""
+    prepare_generators("""
+[projects]
+glob = "src/*"
+
+[beta]
+path = "/tmp"
+""")
+    labels = list_labels()
+    assert sorted(labels) == ["alpha", "beta"], f"Expected alpha and beta but got: {labels}"
+    ret, out, err = helper.run(["--config-file", FILE_GEN_CONFIG, "--history-file", helper.FILE_HISTORY, "--list"], env=ENV)
+    assert os.path.join(DIR_GEN, "src", "alpha") in out, f"Expected the generated path in: {out}"
+    assert os.path.join(DIR_GEN, "src", "beta") not in out, f"Expected the explicit beta to win in: {out}"
+
+def test_scan_generator_with_label_template():
+    """Test that a scan finds directories with the marker and fills in the label template."""
+    prepare_generators("""
+[work]
+scan = { root = "work", depth = 2, marker = ".git" }
+label = "{parent}-{name}"
+""")
+    labels = list_labels()
+    assert sorted(labels) == ["team1-r1", "team2-r1"], f"Expected the repositories but got: {labels}"
+
+def test_completion_uses_cached_expansion():
+    """Test that completion reuses the cached expansion instead of scanning again."""
+    prepare_generators("""
+[projects]
+glob = "src/*"
+""")
+    list_labels()
+    os.makedirs(os.path.join(DIR_GEN, "src", "gamma"))
+    ret, out, err = helper.run(["--complete", "--config-file", FILE_GEN_CONFIG, ""], env=ENV)
+    labels = [line.split("\t")[0] for line in out.splitlines()]
+    assert "gamma" not in labels, f"Expected the cached expansion but got: {labels}"
+    assert "gamma" in list_labels(), "Expected a changed root to be scanned again"
+
+def test_generator_with_path_is_rejected():
+    """Test that a generator entry cannot also have a path."""
+    prepare_generators("""
+[projects]
+glob = "src/*"
+path = "/tmp"
+""")
+    ret, out, err = helper.run(["--config-file", FILE_GEN_CONFIG, "--list"], env=ENV)
+    assert ret != 0, "Expected the configuration to be rejected"
+    assert "[projects]" in out, f"Expected the table to be named in: {out}"
@@ -0,0 +1,121 @@
+# test for stable destination numbers (stable_ids)
+import json
+import goto_helper as helper
+
+FILE_IDS_CONFIG = "/tmp/goto/ids.toml"
+FILE_IDS_HISTORY = "/tmp/goto/ids-history.json"
+
+IDS_CONFIG = """
+[settings]
+status = false
+stable_ids = true
+[alpha]
+path = "/tmp/goto/dir1"
+[beta]
+path = "/tmp/goto/dir2"
+id = 7
+[gamma]
+path = "/tmp/goto/dir3"
+"""
+
+def run_ids(args):
+    return helper.run([
+        "--config-file", FILE_IDS_CONFIG,
+        "--history-file", FILE_IDS_HISTORY,
+        "--lang", "en",
+    ] + args)
+
+def reset_ids(config):
+    helper.prepare_test()
+    helper.create_config(FILE_IDS_CONFIG, config)
+    helper.create_history(FILE_IDS_HISTORY, [
+        {"label": "gamma", "last_used": "2025-01-01T12:00:02Z"},
+        {"label": "beta", "last_used": "2025-01-01T12:00:01Z"},
+    ])
+
+def test_ids_allocated_and_stored():
+    """Test that IDs are configured or allocated, stored and kept."""
+    reset_ids(IDS_CONFIG)
+    ret, out, err = run_ids(["--list", "--json"])
+    assert ret == 0, f"Command failed with error: {err}"
+    ids = {item["label"]: item["id"] for item in json.loads(out)}
+    assert ids == {"alpha": 1, "beta": 7, "gamma": 2}, f"Unexpected IDs: {ids}"
+    with open(FILE_IDS_HISTORY, encoding="utf-8") as f:
+        assert json.load(f)["ids"] == ids, "Expected the IDs in the history file"
+
+    # A removed destination keeps its number reserved
+    helper.create_config(FILE_IDS_CONFIG, IDS_CONFIG.replace("[alpha]", "[delta]"))
+    ret, out, err = run_ids(["--list", "--json"])
+    ids = {item["label"]: item["id"] for item in json.loads(out)}
+    assert ids == {"delta": 3, "beta": 7, "gamma": 2}, f"Unexpected IDs: {ids}"
+
+def test_ids_select():
+    """Test that the menu keeps its order but shows and accepts the IDs."""
+    reset_ids(IDS_CONFIG)
+    ret, out, err = run_ids(["--list-label"])
+    assert out.split() == ["gamma", "beta", "alpha"], f"Expected the history order but got: {out}"
+
+    ret, out, err = run_ids(["--keys", "7", "--snapshots"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "2 gamma → /tmp/goto/dir3" in out and "7 beta " in out, f"Expected the IDs in the menu but got: {out}"
+    assert out.strip().endswith("chosen\tbeta\t/tmp/goto/dir2"), f"Expected beta but got: {out}"
+
+    ret, out, err = helper.run(["--config-file", FILE_IDS_CONFIG, "--history-file", FILE_IDS_HISTORY,
+                                "--lang", "en", "7"], env={"SHELL": "/bin/true"})
+    assert "Found destination: beta" in out, f"Expected beta but got: {out}"
+
+def test_ids_invalid():
+    """Test that a duplicated id is a configuration error."""
+    reset_ids(IDS_CONFIG.replace('path = "/tmp/goto/dir3"', 'path = "/tmp/goto/dir3"\nid = 7'))
+    ret, out, err = run_ids(["--list"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "[gamma] id: 7 is also used by [beta]" in out, f"Expected an id error but got: {out}"
+
+def test_positional_command_warning():
+    """Test that a positional number running a command is warned about."""
+    helper.prepare_test()
+    helper.create_config(FILE_IDS_CONFIG, """
+[settings]
+status = false
+[build]
+path = "/tmp/goto/dir1"
+command = "true"
+""")
+    ret, out, err = helper.run(["--config-file", FILE_IDS_CONFIG, "--history-file", FILE_IDS_HISTORY,
+                                "--lang", "en", "1"], env={"SHELL": "/bin/true"})
+    assert "1 is a position in the menu order" in err, f"Expected a warning but got: {err}"
+
+    ret, out, err = helper.run(["--config-file", FILE_IDS_CONFIG, "--history-file", FILE_IDS_HISTORY,
+                                "--lang", "en", "build"], env={"SHELL": "/bin/true"})
+    assert "position" not in err, f"Expected no warning for a label but got: {err}"
+
+def test_ids_of_removed_destinations():
+    """Test that a removed destination keeps its number until its label is deleted from ids."""
+    reset_ids(IDS_CONFIG)
+    run_ids(["--list"])
+    helper.create_config(FILE_IDS_CONFIG, IDS_CONFIG.replace("[alpha]", "[delta]"))
+    run_ids(["--list"])
+    with open(FILE_IDS_HISTORY, encoding="utf-8") as f:
+        history = json.load(f)
+    assert history["ids"]["alpha"] == 1, f"Expected alpha to keep its number but got: {history['ids']}"
+
+    # Deleting the label from ids frees its number for new destinations
+    del history["ids"]["alpha"]
+    del history["ids"]["delta"]
+    with open(FILE_IDS_HISTORY, "w", encoding="utf-8") as f:
+        json.dump(history, f)
+    ret, out, err = run_ids(["--list", "--json"])
+    ids = {item["label"]: item["id"] for item in json.loads(out)}
+    assert ids["delta"] == 1, f"Expected delta to get the freed number but got: {ids}"
+
+def test_ids_keep_damaged_history():
+    """Test that a history file that cannot be read is not overwritten with new IDs."""
+    reset_ids(IDS_CONFIG)
+    with open(FILE_IDS_HISTORY, "w", encoding="utf-8") as f:
+        f.write('{"entries": [')
+    ret, out, err = run_ids(["--list", "--json"])
+    assert ret == 0, f"Command failed with error: {err}"
+    ids = {item["label"]: item["id"] for item in json.loads(out)}
+    assert ids == {"alpha": 1, "beta": 7, "gamma": 2}, f"Unexpected IDs: {ids}"
+    with open(FILE_IDS_HISTORY, encoding="utf-8") as f:
+        assert f.read() == '{"entries": [', "Expected the damaged history file to be left untouched"
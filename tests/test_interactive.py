@@ -0,0 +1,24 @@
+# test for the interactive menu without a terminal
+import goto_helper as helper
+
+def test_menu_without_terminal():
+    """Test that the menu falls back to line input when stdin is not a terminal."""
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY,
+    ], input_text="0\n")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "raw mode" not in out + err, f"Expected no raw mode error but got: {out.strip()}"
+    assert "/tmp/goto/dir1" in out, f"Expected the destination list but got: {out.strip()}"
+
+def test_menu_without_terminal_retries():
+    """Test that a choice piped after an invalid one is still read."""
+    helper.prepare_test()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY,
+        "--lang", "en",
+    ], input_text="bogus\ndir2\n", env={"SHELL": "/bin/true"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "Invalid input" in out, f"Expected the invalid choice to be reported but got: {out}"
+    assert "Destination: dir2" in out, f"Expected dir2 to be chosen but got: {out}"
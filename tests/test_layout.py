@@ -0,0 +1,73 @@
+# test for the column layout of the menu, --list and --history
+import unicodedata
+import goto_helper as helper
+
+FILE_LAYOUT_CONFIG = "/tmp/goto/layout.toml"
+
+LAYOUT_CONFIG = """
+[settings]
+status = false
+{settings}
+[ab]
+path = "/tmp/goto/dir1"
+shortcut = "a"
+["日本語ラベル"]
+path = "/tmp/goto/dir2"
+["🚀rocket"]
+path = "/tmp/goto/dir3"
+[averyveryverylonglabel]
+path = "/tmp/goto/dir1"
+"""
+
+def run_layout(settings, args):
+    helper.prepare_test()
+    helper.create_config(FILE_LAYOUT_CONFIG, LAYOUT_CONFIG.format(settings=settings))
+    helper.create_history(helper.FILE_HISTORY, [
+        {"label": "日本語ラベル", "last_used": "2025-01-01T12:00:03Z"},
+        {"label": "ab", "last_used": "2025-01-01T12:00:02Z"},
+    ])
+    return helper.run(["--config-file", FILE_LAYOUT_CONFIG, "--history-file", helper.FILE_HISTORY,
+                       "--lang", "en"] + args)
+
+def display_width(text):
+    """Return the width of text in terminal cells."""
+    return sum(2 if unicodedata.east_asian_width(c) in "WF" else 1 for c in text)
+
+def column_of(line, marker):
+    """Return the display column at which marker starts in line."""
+    return display_width(line[:line.index(marker)])
+
+def test_list_aligns_wide_labels():
+    """Test that --list aligns the paths after CJK and emoji labels."""
+    ret, out, err = run_layout('columns = ["number", "label", "shortcut", "path"]', ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    lines = [line for line in out.splitlines() if "→" in line]
+    assert len(lines) == 4, f"Expected four rows but got: {out}"
+    assert len({column_of(line, "→") for line in lines}) == 1, f"Expected aligned paths but got:\n{out}"
+
+def test_menu_truncates_labels():
+    """Test that label_width truncates labels in the menu by display width."""
+    ret, out, err = run_layout('label_width = 10', ["--keys", "esc", "--snapshots"])
+    lines = [line for line in out.splitlines() if "→" in line]
+    assert any("averyve..." in line for line in lines), f"Expected a truncated label but got:\n{out}"
+    assert any("日本語..." in line for line in lines), f"Expected a truncated wide label but got:\n{out}"
+    assert len({column_of(line, "→") for line in lines}) == 1, f"Expected aligned paths but got:\n{out}"
+
+def test_history_columns():
+    """Test that history_columns selects and aligns the --history columns."""
+    ret, out, err = run_layout('history_columns = ["label", "used-at"]', ["--history"])
+    assert ret == 0, f"Command failed with error: {err}"
+    lines = [line for line in out.splitlines() if "2025" in line]
+    assert lines[0].startswith("日本語ラベル ") and lines[1].startswith("ab "), f"Expected the labels first but got:\n{out}"
+    assert "→" not in out, f"Expected no path column but got:\n{out}"
+    assert len({column_of(line, "2025") for line in lines}) == 1, f"Expected aligned timestamps but got:\n{out}"
+
+def test_unknown_columns():
+    """Test that unknown columns are configuration errors."""
+    for settings, detail in [
+        ('columns = ["number", "paths"]', '[settings] columns: unknown column "paths"'),
+        ('history_columns = ["when"]', '[settings] history_columns: unknown column "when"'),
+    ]:
+        ret, out, err = run_layout(settings, ["--list"])
+        assert ret == 1, f"Expected exit status 1 for {settings} but got: {ret}"
+        assert detail in out, f"Expected {detail} but got: {out}"
@@ -0,0 +1,79 @@
+# test for destinations from .goto.toml files above the current directory
+import os
+import json
+import goto_helper as helper
+
+DIR_REPO = "/tmp/goto/mono"
+
+def prepare_repo():
+    """Create a repository with a .goto.toml and relative paths."""
+    helper.prepare_test()
+    os.makedirs(os.path.join(DIR_REPO, "services", "api"), exist_ok=True)
+    os.makedirs(os.path.join(DIR_REPO, "web"), exist_ok=True)
+    helper.create_config(os.path.join(DIR_REPO, ".goto.toml"), """
+[api]
+path = "services/api"
+command = "echo local-command"
+
+[web]
+path = "web"
+""")
+
+def test_local_entries_are_scoped():
+    """Test that local entries are merged with their scope and relative paths resolved."""
+    prepare_repo()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--list",
+    ], cwd=os.path.join(DIR_REPO, "web"))
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "dir1" in out, f"Expected the personal entries in: {out}"
+    assert "mono:api" in out and os.path.join(DIR_REPO, "services", "api") in out, f"Expected the local entry in: {out}"
+
+def test_local_command_needs_confirmation():
+    """Test that a local command is skipped unless confirmed and the scoped label is recorded."""
+    prepare_repo()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en", "api",
+    ], input_text="n\n", env={"SHELL": "/bin/true", "XDG_STATE_HOME": "/tmp/goto/local-state"}, cwd=DIR_REPO)
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "echo local-command" in out, f"Expected the command to be shown in: {out}"
+    assert "Command skipped" in out, f"Expected the command to be skipped in: {out}"
+    with open(helper.FILE_HISTORY) as f:
+        labels = [entry["label"] for entry in json.load(f)["entries"]]
+    assert "mono:api" in labels, f"Expected the scoped label in the history but got: {labels}"
+
+def test_local_configs_outside_repo():
+    """Test that no local entries appear outside the repository."""
+    prepare_repo()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--list-label",
+    ], cwd="/tmp")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "mono:api" not in out, f"Expected no local entries in: {out}"
+
+def test_local_shortcut_cannot_take_personal_one():
+    """Test that a local shortcut clashing with a personal one is dropped with a warning."""
+    prepare_repo()
+    personal = "/tmp/goto/local-personal.toml"
+    helper.create_config(personal, """
+[home]
+path = "/tmp/goto/dir1"
+shortcut = "h"
+""")
+    helper.create_config(os.path.join(DIR_REPO, ".goto.toml"), """
+[evil]
+path = "web"
+shortcut = "h"
+command = "echo evil"
+""")
+    args = ["--config-file", personal, "--history-file", helper.FILE_HISTORY, "--lang", "en"]
+    ret, out, err = helper.run(args + ["--keys", "h"], cwd=DIR_REPO)
+    assert out.strip() == "chosen\thome\t/tmp/goto/dir1", f"Expected the personal shortcut to win but got: {out}"
+    assert "Ignoring shortcut 'h' of local destination 'mono:evil'" in err, f"Expected a warning but got: {err}"
+
+    ret, out, err = helper.run(args + ["h"], env={"SHELL": "/bin/true"}, cwd=DIR_REPO)
+    assert "Found destination: home" in out, f"Expected the personal destination but got: {out}"
+
+    ret, out, err = helper.run(args + ["check"], cwd=DIR_REPO)
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert '[mono:evil] shortcut "h" clashes with the personal shortcut of [home]' in out, f"Expected a problem but got: {out}"
@@ -0,0 +1,85 @@
+# test for the bundled message catalogs
+import os
+import re
+import json
+import goto_helper as helper
+
+DIR_LOCALES = os.path.join(helper.DIR_ROOT, "go", "locales")
+FILE_LOCALE_GO = os.path.join(helper.DIR_ROOT, "go", "locale.go")
+
+def load_catalog(name):
+    with open(os.path.join(DIR_LOCALES, name), "r", encoding="utf-8") as f:
+        return json.load(f)
+
+def message_fields():
+    """Return the field names of the Messages struct."""
+    with open(FILE_LOCALE_GO, "r", encoding="utf-8") as f:
+        src = f.read()
+    struct = re.search(r"type Messages struct \{(.*?)\n\}", src, re.S).group(1)
+    return re.findall(r"^\t([A-Z]\w*)\s+string", struct, re.M)
+
+def format_verbs(text):
+    return re.findall(r"%[-+# 0-9.*]*[a-zA-Z%]", text)
+
+def test_english_catalog_complete():
+    """Test that the English catalog defines every message."""
+    catalog = load_catalog("en.json")
+    missing = [key for key in message_fields() if key not in catalog]
+    assert not missing, f"Missing keys in en.json: {missing}"
+
+def is_regional(name):
+    """Regional catalogs such as es-MX.json only override keys of their base language."""
+    return re.search(r"-([A-Z]{2}|[0-9]{3})\.json$", name) is not None
+
+def test_bundled_catalogs_complete():
+    """Test that every bundled catalog has the same keys and format verbs as English."""
+    english = load_catalog("en.json")
+    names = sorted(os.listdir(DIR_LOCALES))
+    for name in names:
+        catalog = load_catalog(name)
+        unknown = [key for key in catalog if key not in english]
+        assert not unknown, f"Unknown keys in {name}: {unknown}"
+        if is_regional(name):
+            base = name.rsplit("-", 1)[0] + ".json"
+            assert base in names, f"Missing base catalog {base} for {name}"
+        else:
+            missing = [key for key in english if key not in catalog]
+            assert not missing, f"Missing keys in {name}: {missing}"
+        for key, text in catalog.items():
+            assert format_verbs(text) == format_verbs(english[key]), \
+                f"Format verbs of {key} in {name} differ from en.json: {text!r}"
+
+def test_language_negotiation():
+    """Test that LC_ALL wins over LANG and regional locales use their variant."""
+    cases = [
+        ({"LC_ALL": "ja_JP.UTF-8", "LANG": "en_US.UTF-8"}, "ディレクトリ"),
+        ({"LC_ALL": "", "LANG": "zh_TW.UTF-8"}, "快速切換目錄"),
+        ({"LC_ALL": "", "LANG": "zh_CN.UTF-8"}, "快速导航目录"),
+        ({"LC_ALL": "", "LANG": "fr_FR.UTF-8", "LANGUAGE": "fr:ko"}, "디렉토리"),
+        ({"LC_ALL": "", "LANG": "C", "LANGUAGE": "ja"}, "Navigate directories"),
+    ]
+    for env, expected in cases:
+        env = dict({"LANGUAGE": "", "LC_MESSAGES": ""}, **env)
+        ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--help"], env=env)
+        assert ret == 0, f"Command failed with error: {err}"
+        assert expected in out.split("\n")[0], f"Expected {expected!r} for {env} but got: {out.split(chr(10))[0]}"
+
+def test_help_follows_language():
+    """Test that the help text has no hard-coded messages of another language."""
+    english = {"LANG": "en_US.UTF-8", "LANGUAGE": "", "LC_ALL": "", "LC_MESSAGES": ""}
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--help"], env=english)
+    assert ret == 0, f"Command failed with error: {err}"
+    assert not re.search(r"[぀-ヿ]", out), f"Expected no Japanese text but got: {out}"
+
+def test_user_catalog_in_config_dir():
+    """Test that user catalogs are read from the locales directory next to the configuration."""
+    helper.prepare_test()
+    dir_catalogs = "/tmp/goto/xdg-config/goto/locales"
+    os.makedirs(dir_catalogs, exist_ok=True)
+    with open(os.path.join(dir_catalogs, "fr.json"), "w", encoding="utf-8") as f:
+        json.dump({"FoundDestination": "Destination trouvée :"}, f, ensure_ascii=False)
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY,
+                                "--lang", "fr", "dir1"],
+                               env={"XDG_CONFIG_HOME": "/tmp/goto/xdg-config", "SHELL": "/bin/true"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "Destination trouvée : dir1" in out, f"Expected the user catalog but got: {out}"
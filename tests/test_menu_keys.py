@@ -0,0 +1,102 @@
+# test for the cursor menu driven by scripted keys
+import goto_helper as helper
+
+FILE_KEYS_CONFIG = "/tmp/goto/keys.toml"
+
+def reset_history():
+    helper.create_history(helper.FILE_HISTORY, [
+        {"label": "dir1", "last_used": "2025-01-01T12:00:03Z"},
+        {"label": "dir2", "last_used": "2025-01-01T12:00:02Z"},
+        {"label": "dir3", "last_used": "2025-01-01T12:00:01Z"},
+    ])
+
+def play(config, keys, *options):
+    return helper.run([
+        "--config-file", config,
+        "--history-file", helper.FILE_HISTORY,
+        "--keys", keys,
+    ] + list(options))
+
+def test_keys_select():
+    """Test that moving the cursor and pressing Enter chooses the entry."""
+    reset_history()
+    ret, out, err = play(helper.FILE_CONFIG, "down,down,enter")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.strip() == "chosen\tdir3\t/tmp/goto/dir3", f"Expected dir3 but got: {out.strip()}"
+
+def test_keys_cancel():
+    """Test that the exit key cancels the menu."""
+    reset_history()
+    ret, out, err = play(helper.FILE_CONFIG, "down,0")
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert out.strip() == "cancelled", f"Expected cancelled but got: {out.strip()}"
+
+def test_keys_custom_bindings():
+    """Test that key bindings from the [keys] section are used."""
+    reset_history()
+    helper.create_config(FILE_KEYS_CONFIG, """
+[keys]
+down = "n"
+select = "space"
+[settings]
+status = false
+[dir1]
+path = "/tmp/goto/dir1"
+[dir2]
+path = "/tmp/goto/dir2"
+""")
+    ret, out, err = play(FILE_KEYS_CONFIG, "down,n,space", "--snapshots")
+    lines = out.strip().split("\n")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert lines[-1] == "chosen\tdir2\t/tmp/goto/dir2", f"Expected dir2 but got: {lines[-1]}"
+    frame = lines[lines.index("--- frame 2: n ---") + 1:]
+    assert "> 2 dir2 → /tmp/goto/dir2" in frame, f"Expected dir2 to be selected but got: {frame}"
+
+def test_shadowed_shortcut_warning():
+    """Test that a shortcut hidden by a key binding is only reported by goto check."""
+    reset_history()
+    helper.create_config(FILE_KEYS_CONFIG, """
+[settings]
+status = false
+[jay]
+path = "/tmp/goto/dir1"
+shortcut = "j"
+""")
+    args = ["--config-file", FILE_KEYS_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en"]
+    ret, out, err = helper.run(args + ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "shadows" not in err, f"Expected no warning for --list but got: {err}"
+
+    ret, out, err = helper.run(args + ["check"])
+    assert ret == 0, f"Expected the check to pass but got: {ret}"
+    assert "key 'j' is bound to 'down' and shadows the shortcut of 'jay'" in out, f"Expected a warning but got: {out}"
+
+FILE_NUMBERS_CONFIG = "/tmp/goto/numbers.toml"
+
+def create_numbers_config():
+    """Create twelve destinations, e01 to e12, so that numbers have two digits."""
+    helper.prepare_test()
+    helper.create_history(helper.FILE_HISTORY, [])
+    config = "[settings]\nstatus = false\n"
+    for i in range(1, 13):
+        config += f'[e{i:02d}]\npath = "/tmp/goto/dir{(i - 1) % 3 + 1}"\n'
+    helper.create_config(FILE_NUMBERS_CONFIG, config)
+
+def test_keys_multi_digit():
+    """Test that a digit waits for the next one while a longer number is possible."""
+    create_numbers_config()
+    for keys, label in [("1,2", "e12"), ("1,0", "e10"), ("1,wait", "e01"), ("2", "e02")]:
+        ret, out, err = play(FILE_NUMBERS_CONFIG, keys)
+        assert ret == 0, f"Command failed with error: {err}"
+        assert out.split("\t")[:2] == ["chosen", label], f"Expected {label} for {keys} but got: {out.strip()}"
+
+def test_keys_number_out_of_range():
+    """Test that a number beyond the entries is discarded."""
+    create_numbers_config()
+    ret, out, err = play(FILE_NUMBERS_CONFIG, "1,5")
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert out.strip() == "none", f"Expected nothing chosen but got: {out.strip()}"
+
+    # The digits typed after the discarded number start a new one
+    ret, out, err = play(FILE_NUMBERS_CONFIG, "1,5,3")
+    assert out.split("\t")[:2] == ["chosen", "e03"], f"Expected e03 but got: {out.strip()}"
@@ -0,0 +1,91 @@
+# test for nested goto shells
+import os
+import json
+import stat
+import goto_helper as helper
+
+FILE_SHELL = "/tmp/goto/print-env.sh"
+
+def create_shell():
+    with open(FILE_SHELL, "w") as f:
+        f.write('#!/bin/sh\necho "DEPTH=$GOTO_DEPTH LABEL=$GOTO_LABEL FUNCTION=$GOTO_SHELL_FUNCTION"\n')
+    os.chmod(FILE_SHELL, os.stat(FILE_SHELL).st_mode | stat.S_IEXEC)
+
+def test_depth_and_label_exported():
+    """Test that the spawned shell gets GOTO_DEPTH and GOTO_LABEL."""
+    helper.prepare_test()
+    create_shell()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1",
+    ], env={"SHELL": FILE_SHELL, "GOTO_DEPTH": ""})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "DEPTH=1 LABEL=dir1" in out, f"Expected depth 1 but got: {out.strip()}"
+    assert "(goto depth 1)" in out, f"Expected the depth in the banner but got: {out.strip()}"
+
+def test_shell_function_not_inherited():
+    """Test that the spawned shell does not inherit GOTO_SHELL_FUNCTION."""
+    helper.prepare_test()
+    create_shell()
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1",
+    ], env={"SHELL": FILE_SHELL, "GOTO_DEPTH": "", "GOTO_SHELL_FUNCTION": "1"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "LABEL=dir1 FUNCTION=\n" in out, f"Expected GOTO_SHELL_FUNCTION to be unset but got: {out.strip()}"
+
+def test_missing_directory_not_recorded():
+    """Test that a destination whose directory is missing is not added to the history."""
+    helper.prepare_test()
+    with open(helper.FILE_CONFIG, "a") as f:
+        f.write('[gone]\npath = "/tmp/goto/does-not-exist"\n')
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "--lang", "en", "gone",
+    ], env={"SHELL": "/bin/true"})
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "does not exist" in out, f"Expected a missing directory but got: {out}"
+    with open(helper.FILE_HISTORY) as f:
+        labels = [entry["label"] for entry in json.load(f)["entries"]]
+    assert "gone" not in labels, f"Expected gone not to be recorded but got: {labels}"
+
+def test_nested_warning():
+    """Test that the warn policy warns inside a goto shell."""
+    helper.prepare_test()
+    create_shell()
+    with open(helper.FILE_CONFIG, "a") as f:
+        f.write('[settings]\nnested_shell = "warn"\n')
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir2",
+    ], env={"SHELL": FILE_SHELL, "GOTO_DEPTH": "2"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "DEPTH=3 LABEL=dir2" in out, f"Expected depth 3 but got: {out.strip()}"
+    assert "depth 2" in err, f"Expected a warning but got: {err.strip()}"
+
+FILE_FUNCTION_SHELL = "/tmp/goto/function-shell.sh"
+
+def test_replace_keeps_depth():
+    """Test that the replace policy swaps the goto shell at its depth."""
+    helper.prepare_test()
+    with open(helper.FILE_CONFIG, "a") as f:
+        f.write('[settings]\nnested_shell = "replace"\n')
+    # A shell that runs goto dir2 through the shell function, as typed at depth 1
+    with open(FILE_FUNCTION_SHELL, "w") as f:
+        f.write(f"""#!/bin/sh
+if [ "$GOTO_LABEL" = dir1 ]; then
+  GOTO_SHELL_FUNCTION=1 {helper.FILE_GOTO} --config-file {helper.FILE_CONFIG} --history-file {helper.FILE_HISTORY} dir2
+  [ $? -eq 75 ] && exit 0
+  echo "NOT REPLACED"
+else
+  echo "DEPTH=$GOTO_DEPTH LABEL=$GOTO_LABEL"
+fi
+""")
+    os.chmod(FILE_FUNCTION_SHELL, os.stat(FILE_FUNCTION_SHELL).st_mode | stat.S_IEXEC)
+    ret, out, err = helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "dir1",
+    ], env={"SHELL": FILE_FUNCTION_SHELL, "GOTO_DEPTH": "", "XDG_STATE_HOME": "/tmp/goto/nesting-state"})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "DEPTH=1 LABEL=dir2" in out, f"Expected dir2 at depth 1 but got: {out.strip()}"
+    assert "NOT REPLACED" not in out, f"Expected the shell to be replaced but got: {out.strip()}"
@@ -0,0 +1,44 @@
+# test for the lookup of the configuration and history files
+import os
+import goto_helper as helper
+
+DIR_XDG_CONFIG = "/tmp/goto/xdg-config"
+DIR_XDG_STATE = "/tmp/goto/xdg-state"
+
+def test_env_selects_files():
+    """Test that GOTO_CONFIG and GOTO_HISTORY select the files."""
+    helper.prepare_test()
+    ret, out, err = helper.run(["--list"], env={
+        "GOTO_CONFIG": helper.FILE_CONFIG,
+        "GOTO_HISTORY": helper.FILE_HISTORY,
+    })
+    assert ret == 0, f"Command failed with error: {err}"
+    for label in ["dir1", "dir2", "dir3"]:
+        assert label in out, f"Expected {label} from the test configuration but got: {out}"
+
+def test_help_shows_files_in_use():
+    """Test that the help prints the configuration and history files in use."""
+    helper.prepare_test()
+    ret, out, err = helper.run(["--lang", "en", "--help"], env={
+        "GOTO_CONFIG": helper.FILE_CONFIG,
+        "GOTO_HISTORY": helper.FILE_HISTORY,
+    })
+    assert ret == 0, f"Command failed with error: {err}"
+    assert f"Configuration file: {helper.FILE_CONFIG}" in out, f"Expected the configuration file in: {out}"
+    assert f"History file: {helper.FILE_HISTORY}" in out, f"Expected the history file in: {out}"
+
+def test_xdg_config_home():
+    """Test that an existing configuration below XDG_CONFIG_HOME is used."""
+    helper.prepare_test()
+    os.makedirs(os.path.join(DIR_XDG_CONFIG, "goto"), exist_ok=True)
+    helper.create_config(os.path.join(DIR_XDG_CONFIG, "goto", "config.toml"), """
+[xdg_only]
+path = "/tmp"
+""")
+    env = dict(os.environ)
+    env.pop("GOTO_CONFIG", None)
+    env.pop("GOTO_PROFILE", None)
+    env.update({"XDG_CONFIG_HOME": DIR_XDG_CONFIG, "XDG_STATE_HOME": DIR_XDG_STATE})
+    ret, out, err = helper.run(["--list"], env=env)
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "xdg_only" in out, f"Expected the XDG configuration but got: {out}"
@@ -0,0 +1,57 @@
+# test for goto-NAME plugins on PATH
+import os
+import json
+import shutil
+import goto_helper as helper
+
+DIR_PLUGINS = "/tmp/goto/plugins"
+
+def prepare_plugins(settings=""):
+    """Create plugins named like a new command and like a destination."""
+    helper.prepare_test()
+    shutil.rmtree(DIR_PLUGINS, ignore_errors=True)
+    os.makedirs(DIR_PLUGINS)
+    for name, body in {
+        "goto-report": 'echo "report $* $GOTO_CONFIG $GOTO_HISTORY $GOTO_LANG"; "$GOTO_BIN" --list --json; exit 3',
+        "goto-dir1": "echo plugin-dir1",
+    }.items():
+        path = os.path.join(DIR_PLUGINS, name)
+        with open(path, "w") as f:
+            f.write("#!/bin/sh\n" + body + "\n")
+        os.chmod(path, 0o755)
+    if settings:
+        with open(helper.FILE_CONFIG, "a") as f:
+            f.write("\n[settings]\n" + settings + "\n")
+
+def run(args, input_text=None):
+    env = {"PATH": DIR_PLUGINS + os.pathsep + os.environ["PATH"], "SHELL": "/bin/true"}
+    return helper.run(["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en"] + args,
+                      input_text=input_text, env=env)
+
+def test_plugin_environment():
+    """Test that a plugin gets its arguments, environment and exit status."""
+    prepare_plugins()
+    ret, out, err = run(["report", "weekly"])
+    assert ret == 3, f"Expected the exit status of the plugin but got {ret}: {err}"
+    first, rest = out.split("\n", 1)
+    assert first == f"report weekly {helper.FILE_CONFIG} {helper.FILE_HISTORY} en", f"Unexpected plugin output: {first}"
+    labels = [entry["label"] for entry in json.loads(rest)]
+    assert "dir1" in labels, f"Expected the destinations in the JSON dump but got: {labels}"
+
+def test_destination_wins_by_default():
+    """Test that a destination wins over a plugin of the same name unless configured otherwise."""
+    prepare_plugins()
+    ret, out, err = run(["dir1"])
+    assert "plugin-dir1" not in out, f"Expected the destination to win but got: {out}"
+
+    prepare_plugins('plugin_precedence = "plugins"')
+    ret, out, err = run(["dir1"])
+    assert out.strip() == "plugin-dir1", f"Expected the plugin to win but got: {out}"
+
+def test_help_lists_plugins():
+    """Test that the help lists plugins and marks hidden ones."""
+    prepare_plugins()
+    ret, out, err = run(["help"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "goto report" in out, f"Expected the plugin in the help: {out}"
+    assert "goto dir1" in out and "hidden by a destination" in out, f"Expected the hidden plugin to be marked: {out}"
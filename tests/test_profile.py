@@ -0,0 +1,22 @@
+# test for profiles and the selected files
+import json
+import goto_helper as helper
+
+def test_label_mode_honors_history_file():
+    """Test that choosing in the menu records history in --history-file."""
+    helper.prepare_test()
+    ret, out, err = helper.run([
+        "-l", "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY,
+    ], input_text="dir3\n", env={"SHELL": "/bin/true"})
+    assert ret == 0, f"Command failed with error: {err}"
+    with open(helper.FILE_HISTORY) as f:
+        entries = json.load(f)["entries"]
+    latest = max(entries, key=lambda entry: entry["last_used"])
+    assert latest["label"] == "dir3", f"Expected dir3 to be the latest entry but got: {entries}"
+
+def test_unknown_profile():
+    """Test that an unknown profile is reported instead of creating a configuration."""
+    ret, out, err = helper.run(["--profile", "no-such-profile", "--list"])
+    assert ret != 0, "Expected an unknown profile to fail"
+    assert "no-such-profile" in err, f"Expected the profile in the error but got: {err.strip()}"
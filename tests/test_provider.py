@@ -0,0 +1,67 @@
+# test for provider entries
+import os
+import shutil
+import time
+import goto_helper as helper
+
+DIR_PROVIDER = "/tmp/goto/provider"
+FILE_PROVIDER_CONFIG = os.path.join(DIR_PROVIDER, "goto.toml")
+ENV = {"XDG_CACHE_HOME": os.path.join(DIR_PROVIDER, "cache")}
+
+def run_list(config):
+    """Write the configuration and list its destinations."""
+    shutil.rmtree(DIR_PROVIDER, ignore_errors=True)
+    os.makedirs(os.path.join(DIR_PROVIDER, "svc", "a"))
+    helper.create_config(FILE_PROVIDER_CONFIG, config)
+    return helper.run(["--config-file", FILE_PROVIDER_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en", "--list"], env=ENV)
+
+def test_line_and_json_protocols():
+    """Test that both output formats become destinations with relative paths resolved."""
+    ret, out, err = run_list(r"""
+[lines]
+provider = '''printf "svc-a\tsvc/a\tx\n"'''
+
+[json]
+provider = '''echo '[{"label": "from-json", "path": "/tmp"}]' '''
+""")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "svc-a" in out and os.path.join(DIR_PROVIDER, "svc", "a") in out and "(x)" in out, f"Expected the line entry in: {out}"
+    assert "from-json" in out, f"Expected the JSON entry in: {out}"
+
+def test_failing_provider_warns():
+    """Test that failing and slow providers only produce warnings."""
+    start = time.time()
+    ret, out, err = run_list("""
+[home]
+path = "/tmp"
+
+[broken]
+provider = "exit 3"
+
+[slow]
+provider = "sleep 5"
+timeout = "200ms"
+""")
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "home" in out, f"Expected the other destinations in: {out}"
+    assert "[broken] failed" in err and "[slow] failed" in err, f"Expected warnings in: {err}"
+    assert time.time() - start < 3, "Expected the slow provider to be stopped by its timeout"
+
+def test_local_provider_needs_trust():
+    """Test that a provider in a .goto.toml only runs once trusted."""
+    repo = os.path.join(DIR_PROVIDER, "repo")
+    shutil.rmtree(DIR_PROVIDER, ignore_errors=True)
+    os.makedirs(repo)
+    helper.prepare_test()
+    helper.create_config(os.path.join(repo, ".goto.toml"), """
+[services]
+provider = '''printf "svc\\t.\\n"'''
+""")
+    env = dict(ENV, XDG_STATE_HOME=os.path.join(DIR_PROVIDER, "state"))
+    args = ["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en"]
+    ret, out, err = helper.run(args + ["--list-label"], env=env, cwd=repo)
+    assert "repo:svc" not in out and "not trusted" in err, f"Expected the provider to be skipped: {out} {err}"
+    ret, out, err = helper.run(args + ["trust"], env=env, cwd=repo)
+    assert ret == 0, f"Command failed with error: {err}"
+    ret, out, err = helper.run(args + ["--list-label"], env=env, cwd=repo)
+    assert "repo:svc" in out, f"Expected the trusted provider to run: {out} {err}"
@@ -0,0 +1,89 @@
+# test for the sort setting, --sort and pinned destinations
+import json
+import goto_helper as helper
+
+FILE_SORT_CONFIG = "/tmp/goto/sort.toml"
+FILE_SORT_HISTORY = "/tmp/goto/sort-history.json"
+
+SORT_CONFIG = """
+[settings]
+status = false
+[zeta]
+path = "/tmp/goto/dir1"
+[alpha]
+path = "/tmp/goto/dir2"
+[beta]
+path = "/tmp/goto/dir3"
+"""
+
+def reset_history():
+    helper.create_history(FILE_SORT_HISTORY, [
+        {"label": "alpha", "last_used": "2025-01-01T12:00:03Z", "visits": 1},
+        {"label": "beta", "last_used": "2025-01-01T12:00:01Z", "visits": 30},
+        {"label": "zeta", "last_used": "2025-01-01T12:00:02Z"},
+    ])
+
+def list_labels(*options):
+    ret, out, err = helper.run([
+        "--config-file", FILE_SORT_CONFIG,
+        "--history-file", FILE_SORT_HISTORY,
+        "--lang", "en",
+        "--list-label",
+    ] + list(options))
+    assert ret == 0, f"Command failed with error: {err}"
+    return out.split()
+
+def test_sort_override():
+    """Test that --sort orders the destinations."""
+    helper.prepare_test()
+    helper.create_config(FILE_SORT_CONFIG, SORT_CONFIG)
+    reset_history()
+    assert list_labels() == ["alpha", "zeta", "beta"]
+    assert list_labels("--sort", "history") == ["alpha", "zeta", "beta"]
+    assert list_labels("--sort", "frecency") == ["beta", "alpha", "zeta"]
+    assert list_labels("--sort", "alphabetical") == ["alpha", "beta", "zeta"]
+    assert list_labels("--sort", "config") == ["zeta", "alpha", "beta"]
+
+def test_sort_setting():
+    """Test that the sort setting is used and validated."""
+    helper.prepare_test()
+    helper.create_config(FILE_SORT_CONFIG, SORT_CONFIG.replace("status = false", 'status = false\nsort = "config"'))
+    reset_history()
+    assert list_labels() == ["zeta", "alpha", "beta"]
+    assert list_labels("--sort", "alphabetical") == ["alpha", "beta", "zeta"]
+
+    helper.create_config(FILE_SORT_CONFIG, SORT_CONFIG.replace("status = false", 'status = false\nsort = "random"'))
+    ret, out, err = helper.run(["--config-file", FILE_SORT_CONFIG, "--lang", "en", "--list"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "[settings] sort" in out, f"Expected a sort error but got: {out}"
+
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--lang", "en", "--sort", "random", "--list"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "Unknown sort order: random" in err, f"Expected an error but got: {err}"
+
+def test_pinned_first():
+    """Test that pinned destinations come first in configuration order."""
+    helper.prepare_test()
+    helper.create_config(FILE_SORT_CONFIG, SORT_CONFIG + """
+[pinned-b]
+path = "/tmp/goto"
+pinned = true
+[pinned-a]
+path = "/tmp/goto"
+pinned = true
+""")
+    reset_history()
+    for order in ["history", "frecency", "alphabetical", "config"]:
+        labels = list_labels("--sort", order)
+        assert labels[:2] == ["pinned-b", "pinned-a"], f"Expected pinned entries first with {order} but got: {labels}"
+
+def test_visits_counted():
+    """Test that going to a destination counts the visit."""
+    helper.prepare_test()
+    helper.create_config(FILE_SORT_CONFIG, SORT_CONFIG)
+    reset_history()
+    helper.run(["--config-file", FILE_SORT_CONFIG, "--history-file", FILE_SORT_HISTORY, "alpha"])
+    with open(FILE_SORT_HISTORY, encoding="utf-8") as f:
+        history = json.load(f)
+    visits = {entry["label"]: entry.get("visits") for entry in history["entries"]}
+    assert visits["alpha"] == 2, f"Expected 2 visits of alpha but got: {visits}"
@@ -0,0 +1,48 @@
+# test for the navigation stack
+import json
+import os
+import goto_helper as helper
+
+FILE_STACK = "/tmp/goto/history.stack.json"
+
+def run_in(cwd, args, session="test-session"):
+    env = {"SHELL": "/bin/true", "GOTO_SESSION": session}
+    old = os.getcwd()
+    os.chdir(cwd)
+    try:
+        return helper.run([
+            "--config-file", helper.FILE_CONFIG,
+            "--history-file", helper.FILE_HISTORY,
+        ] + args, env=env)
+    finally:
+        os.chdir(old)
+
+def test_stack_push_and_back():
+    """Test that the origin is pushed and goto - returns to it."""
+    helper.prepare_test()
+    if os.path.exists(FILE_STACK):
+        os.remove(FILE_STACK)
+    os.makedirs("/tmp/goto/dir1/sub", exist_ok=True)
+
+    ret, out, err = run_in("/tmp/goto/dir1/sub", ["dir2"])
+    assert ret == 0, f"Command failed with error: {err}"
+    with open(FILE_STACK) as f:
+        entries = json.load(f)["sessions"]["test-session"]["entries"]
+    assert [(e["label"], e["subpath"]) for e in entries] == [("dir1", "sub")], f"Unexpected stack: {entries}"
+
+    ret, out, err = run_in("/tmp/goto/dir2", ["stack"])
+    assert "dir1/sub" in out, f"Expected the stack entry but got: {out.strip()}"
+
+    ret, out, err = run_in("/tmp/goto/dir2", ["-"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "/tmp/goto/dir1/sub" in out, f"Expected to go back but got: {out.strip()}"
+
+    ret, out, err = run_in("/tmp/goto/dir2", ["-"])
+    assert ret != 0, "Expected an empty stack to fail"
+
+def test_stack_is_per_session():
+    """Test that another session does not see the stack."""
+    helper.prepare_test()
+    run_in("/tmp/goto/dir1", ["dir2"], session="one")
+    ret, out, err = run_in("/tmp/goto/dir2", ["--back", "1"], session="two")
+    assert ret != 0, "Expected the other session to have an empty stack"
@@ -0,0 +1,61 @@
+# test for the status indicators of destinations
+import os
+import stat
+import goto_helper as helper
+
+FILE_STATUS_CONFIG = "/tmp/goto/status.toml"
+DIR_SLOW_BIN = "/tmp/goto/slow-bin"
+DIR_REPO = "/tmp/goto/slow-repo"
+
+STATUS_CONFIG = """
+[settings]
+{settings}
+[home]
+path = "/tmp/goto/dir1"
+[gone]
+path = "/tmp/goto/does-not-exist"
+[repo]
+path = "/tmp/goto/slow-repo"
+"""
+
+def run_status(settings, args, env=None):
+    helper.prepare_test()
+    os.makedirs(os.path.join(DIR_REPO, ".git"), exist_ok=True)
+    helper.create_config(FILE_STATUS_CONFIG, STATUS_CONFIG.format(settings=settings))
+    helper.create_history(helper.FILE_HISTORY, [])
+    return helper.run(["--config-file", FILE_STATUS_CONFIG, "--history-file", helper.FILE_HISTORY,
+                       "--lang", "en"] + args, env=env)
+
+def row(out, label):
+    return next(line for line in out.splitlines() if f" {label} " in line)
+
+def test_status_indicators():
+    """Test the indicators of a directory and a missing path."""
+    ret, out, err = run_status("status = true", ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "📁" in row(out, "home"), f"Expected a directory but got: {out}"
+    assert "📁" in row(out, "repo"), f"Expected a directory but got: {out}"
+    assert "❌" in row(out, "gone"), f"Expected a missing path but got: {out}"
+
+def test_status_timeout():
+    """Test that a check exceeding status_timeout is shown as timed out."""
+    os.makedirs(DIR_SLOW_BIN, exist_ok=True)
+    slow_git = os.path.join(DIR_SLOW_BIN, "git")
+    with open(slow_git, "w") as f:
+        f.write("#!/bin/sh\nsleep 2\n")
+    os.chmod(slow_git, os.stat(slow_git).st_mode | stat.S_IEXEC)
+
+    ret, out, err = run_status('status = true\ngit_status = true\nstatus_timeout = "100ms"', ["--list"],
+                               env={"PATH": DIR_SLOW_BIN + ":" + os.environ["PATH"]})
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "⏳" in row(out, "repo"), f"Expected a timed out check but got: {out}"
+    assert "📁" in row(out, "home"), f"Expected the other checks to finish but got: {out}"
+
+def test_status_hidden_when_piped():
+    """Test that the indicators are left out of piped output unless enabled explicitly."""
+    ret, out, err = run_status("", ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "gone" in out and "📁" not in out and "❌" not in out, f"Expected no indicators but got: {out}"
+
+    ret, out, err = run_status("status = false", ["--list"])
+    assert "📁" not in out, f"Expected no indicators but got: {out}"
@@ -0,0 +1,130 @@
+# test for tags: --tag filters, the tag key of the menu and goto check
+import json
+import goto_helper as helper
+
+FILE_TAGS_CONFIG = "/tmp/goto/tags.toml"
+
+TAGS_CONFIG = """
+[settings]
+status = false
+[api]
+path = "/tmp/goto/dir1"
+tags = ["work", "go"]
+[legacy]
+path = "/tmp/goto/dir2"
+tags = ["work", "archived"]
+[blog]
+path = "/tmp/goto/dir3"
+tags = ["home"]
+"""
+
+def run_tags(args):
+    return helper.run([
+        "--config-file", FILE_TAGS_CONFIG,
+        "--history-file", helper.FILE_HISTORY,
+        "--lang", "en",
+    ] + args)
+
+def test_tag_filter_list():
+    """Test that --tag restricts --list with negation and alternatives."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG)
+    ret, out, err = run_tags(["--tag", "work,!archived", "--list-label"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.split() == ["api"], f"Expected only api but got: {out}"
+
+    ret, out, err = run_tags(["--tag", "go|home", "--list", "--json"])
+    assert ret == 0, f"Command failed with error: {err}"
+    items = json.loads(out)
+    assert sorted(item["label"] for item in items) == ["api", "blog"], f"Unexpected JSON: {out}"
+    assert all(item["tags"] for item in items), f"Expected tags in JSON: {out}"
+
+def test_tag_filter_errors():
+    """Test that an invalid or unmatched tag filter is reported."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG)
+    ret, out, err = run_tags(["--tag", "work,,go", "--list"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "Invalid tag filter" in out, f"Expected an error but got: {out}"
+
+    ret, out, err = run_tags(["--tag", "nothing", "1"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert "No destinations match" in out, f"Expected no matches but got: {out}"
+
+def test_tag_completion():
+    """Test that completion follows --tag and completes tag names."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG)
+    ret, out, err = helper.run(["--complete", "--config-file", FILE_TAGS_CONFIG, "--tag", "home", ""])
+    labels = [line.split("\t")[0] for line in out.splitlines()]
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "blog" in labels and "api" not in labels, f"Unexpected candidates: {labels}"
+
+    ret, out, err = helper.run(["--complete", "--config-file", FILE_TAGS_CONFIG, "--tag", "work,!ar"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "work,!archived" in out.splitlines(), f"Expected work,!archived but got: {out}"
+
+def test_tag_key_cycles():
+    """Test that the tag key narrows the menu to one tag after another."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG)
+    ret, out, err = run_tags(["--keys", "tab,tab,enter"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.strip() == "chosen\tapi\t/tmp/goto/dir1", f"Expected api (#go) but got: {out.strip()}"
+
+    ret, out, err = run_tags(["--keys", "tab,tab,tab,tab,tab", "--snapshots"])
+    frame = out[out.index("--- frame 5: tab ---"):]
+    assert "api" in frame and "blog" in frame, f"Expected all entries after the last tag: {frame}"
+
+def test_group_by_tag():
+    """Test that group_by_tag shows a header above each group."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG.replace(
+        "status = false", "status = false\ngroup_by_tag = true") + """
+[plain]
+path = "/tmp/goto"
+""")
+    ret, out, err = run_tags(["--keys", "0", "--snapshots"])
+    lines = out.splitlines()
+    assert "#home" in lines and "#work" in lines, f"Expected group headers but got: {lines}"
+    assert lines.index("#home") < lines.index("#work") < lines.index("(untagged)"), f"Unexpected group order: {lines}"
+
+def test_check():
+    """Test that goto check reports invalid tags and duplicate shortcuts."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG)
+    ret, out, err = run_tags(["check"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "No problems" in out, f"Expected no problems but got: {out}"
+
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG + """
+[bad]
+path = "/tmp/goto"
+tags = ["two words"]
+shortcut = "b"
+[also]
+path = "/tmp/goto"
+shortcut = "b"
+""")
+    ret, out, err = run_tags(["check"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert '[bad] tags: tag "two words" must not contain spaces' in out, f"Expected a tag problem but got: {out}"
+    assert '[bad] shortcut "b" is also used by [also]' in out, f"Expected a shortcut problem but got: {out}"
+
+def test_check_localized():
+    """Test that the problems reported by goto check follow the language."""
+    helper.prepare_test()
+    helper.create_config(FILE_TAGS_CONFIG, TAGS_CONFIG + """
+[bad]
+path = "/tmp/goto"
+tags = ["two words"]
+shortcut = "b"
+[also]
+path = "/tmp/goto"
+shortcut = "b"
+""")
+    ret, out, err = helper.run(["--config-file", FILE_TAGS_CONFIG, "--history-file", helper.FILE_HISTORY,
+                                "--lang", "ja", "check"])
+    assert ret == 1, f"Expected exit status 1 but got: {ret}"
+    assert '[bad] tags: タグ "two words" に空白は使えません' in out, f"Expected a Japanese tag problem but got: {out}"
+    assert '[bad] ショートカット "b" は [also] でも使われています' in out, f"Expected a Japanese shortcut problem but got: {out}"
@@ -0,0 +1,88 @@
+# test for colour themes, NO_COLOR and the detection of non-terminal output
+import os
+import pty
+import subprocess
+import goto_helper as helper
+
+FILE_THEME_CONFIG = "/tmp/goto/theme.toml"
+
+THEME_CONFIG = """
+[settings]
+status = false
+[theme]
+{theme}
+[home]
+path = "/tmp/goto/dir1"
+shortcut = "h"
+"""
+
+def run_theme(theme, args, env=None):
+    """Run goto with the [theme] section and its output piped."""
+    helper.prepare_test()
+    helper.create_config(FILE_THEME_CONFIG, THEME_CONFIG.format(theme=theme))
+    return helper.run(["--config-file", FILE_THEME_CONFIG, "--history-file", helper.FILE_HISTORY,
+                       "--lang", "en"] + args, env=env)
+
+def run_tty(theme, args, env):
+    """Run goto with its output on a pseudo-terminal and return the output."""
+    helper.prepare_test()
+    helper.create_config(FILE_THEME_CONFIG, THEME_CONFIG.format(theme=theme))
+    master, slave = pty.openpty()
+    run_env = {k: v for k, v in os.environ.items() if k != "NO_COLOR"}
+    run_env.update({"TERM": "xterm"}, **env)
+    process = subprocess.Popen([helper.FILE_GOTO, "--config-file", FILE_THEME_CONFIG,
+                                "--history-file", helper.FILE_HISTORY] + args,
+                               stdout=slave, stderr=slave, stdin=subprocess.DEVNULL, env=run_env)
+    os.close(slave)
+    output = b""
+    while True:
+        try:
+            data = os.read(master, 4096)
+        except OSError:
+            break
+        if not data:
+            break
+        output += data
+    os.close(master)
+    process.wait()
+    return output.decode("utf-8", "replace")
+
+def test_colors_on_terminal_only():
+    """Test that colours are used on a terminal but not when piped."""
+    out = run_tty("", ["--list"], {})
+    assert "\x1b[" in out, f"Expected colours on a terminal but got: {out!r}"
+
+    ret, out, err = run_theme("", ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "\x1b[" not in out, f"Expected no colours when piped but got: {out!r}"
+
+def test_no_color():
+    """Test that NO_COLOR and TERM=dumb disable colours on a terminal."""
+    out = run_tty("", ["--list"], {"NO_COLOR": "1"})
+    assert "home" in out and "\x1b[" not in out, f"Expected no colours with NO_COLOR but got: {out!r}"
+    out = run_tty("", ["--list"], {"TERM": "dumb"})
+    assert "home" in out and "\x1b[" not in out, f"Expected no colours with TERM=dumb but got: {out!r}"
+
+    # Without colours the selected entry of the menu is marked with ">"
+    ret, out, err = run_theme("", ["--keys", "esc", "--snapshots"], env={"NO_COLOR": "1"})
+    assert "> 1 home (h)" in out and "\x1b[" not in out, f"Expected a plain menu but got: {out!r}"
+
+def test_custom_theme():
+    """Test that the colours of the [theme] section are used."""
+    ret, out, err = run_theme('color = "always"\ncolors = "truecolor"\nshortcut = "#ff0000"', ["--list"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert "\x1b[38;2;255;0;0m(h)\x1b[0m" in out, f"Expected a true colour shortcut but got: {out!r}"
+
+    ret, out, err = run_theme('name = "mono"\ncolor = "always"', ["--list"])
+    assert "\x1b[4m(h)\x1b[0m" in out, f"Expected an underlined shortcut but got: {out!r}"
+
+def test_invalid_theme():
+    """Test that invalid [theme] values are configuration errors."""
+    for theme, detail in [
+        ('shortcut = "purple"', '[theme] shortcut: invalid colour "purple"'),
+        ('name = "neon"', 'unknown theme "neon"'),
+        ('color = "sometimes"', 'invalid color mode "sometimes"'),
+    ]:
+        ret, out, err = run_theme(theme, ["--list"])
+        assert ret == 1, f"Expected exit status 1 for {theme} but got: {ret}"
+        assert detail in out, f"Expected {detail} but got: {out}"
@@ -0,0 +1,40 @@
+# test for the trust database of local commands
+import os
+import shutil
+import goto_helper as helper
+from test_local import DIR_REPO, prepare_repo
+
+DIR_STATE = "/tmp/goto/trust-state"
+ENV = {"SHELL": "/bin/true", "XDG_STATE_HOME": DIR_STATE}
+
+def run_api(input_text):
+    return helper.run([
+        "--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--lang", "en", "api",
+    ], input_text=input_text, env=ENV, cwd=DIR_REPO)
+
+def test_approved_command_is_remembered():
+    """Test that an approved command runs without asking until it changes."""
+    prepare_repo()
+    shutil.rmtree(DIR_STATE, ignore_errors=True)
+    ret, out, err = run_api("y\n")
+    assert ret == 0 and "Trust and run it?" in out, f"Expected a prompt but got: {out} {err}"
+    ret, out, err = run_api("")
+    assert ret == 0 and "Trust and run it?" not in out, f"Expected no prompt once trusted but got: {out}"
+
+    helper.create_config(os.path.join(DIR_REPO, ".goto.toml"), '[api]\npath = "services/api"\ncommand = "echo changed"\n')
+    ret, out, err = run_api("n\n")
+    assert "has changed" in out and "Command skipped" in out, f"Expected the changed command to be shown but got: {out}"
+
+def test_trust_and_untrust_commands():
+    """Test that goto trust approves the commands of a file and goto untrust forgets them."""
+    prepare_repo()
+    shutil.rmtree(DIR_STATE, ignore_errors=True)
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--lang", "en", "trust"], env=ENV, cwd=DIR_REPO)
+    assert ret == 0 and "echo local-command" in out, f"Expected the trusted command to be listed but got: {out} {err}"
+    ret, out, err = run_api("")
+    assert "Trust and run it?" not in out, f"Expected no prompt after goto trust but got: {out}"
+
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--lang", "en", "untrust"], env=ENV, cwd=DIR_REPO)
+    assert ret == 0, f"Command failed with error: {err}"
+    ret, out, err = run_api("n\n")
+    assert "Trust and run it?" in out, f"Expected a prompt after goto untrust but got: {out}"
@@ -0,0 +1,89 @@
+# test for goto which
+import json
+import os
+import shutil
+import time
+import goto_helper as helper
+
+def run_which(args):
+    return helper.run([
+        "--config-file", helper.FILE_CONFIG,
+        "--history-file", helper.FILE_HISTORY, "which",
+    ] + args)
+
+def test_which_deepest_destination():
+    """Test that the deepest destination containing the path is printed."""
+    helper.prepare_test()
+    os.makedirs("/tmp/goto/dir1/sub", exist_ok=True)
+    helper.create_config(helper.FILE_CONFIG, """
+[goto]
+path = "/tmp/goto"
+[dir1]
+path = "/tmp/goto/dir1"
+shortcut = "d"
+""")
+    ret, out, err = run_which(["/tmp/goto/dir1/sub"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.strip() == "dir1 (d)", f"Expected dir1 but got: {out.strip()}"
+
+    ret, out, err = run_which(["--json", "/tmp/goto/dir1/sub"])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert json.loads(out) == {
+        "label": "dir1", "shortcut": "d", "path": "/tmp/goto/dir1", "subpath": "sub",
+    }, f"Unexpected JSON: {out.strip()}"
+
+def test_which_symlink():
+    """Test that paths are compared after resolving symlinks."""
+    helper.prepare_test()
+    link = "/tmp/goto/link-to-dir2"
+    if not os.path.islink(link):
+        os.symlink("/tmp/goto/dir2", link)
+    ret, out, err = run_which([link])
+    assert ret == 0, f"Command failed with error: {err}"
+    assert out.strip() == "dir2", f"Expected dir2 but got: {out.strip()}"
+
+def test_which_no_match():
+    """Test that no match exits with status 1 and leaves the history alone."""
+    helper.prepare_test()
+    with open(helper.FILE_HISTORY) as f:
+        history = f.read()
+    ret, out, err = run_which(["/"])
+    assert ret == 1, f"Expected exit status 1 but got {ret}"
+    assert out == "", f"Expected no output but got: {out.strip()}"
+    with open(helper.FILE_HISTORY) as f:
+        assert f.read() == history, "Expected the history to be unchanged"
+
+def test_which_does_not_run_providers():
+    """Test that goto which uses cached provider results instead of running providers."""
+    helper.prepare_test()
+    helper.create_config(helper.FILE_CONFIG, """
+[services]
+provider = 'printf "svc\\t/tmp/goto/dir1\\n"'
+[slow]
+provider = "sleep 3"
+""")
+    env = {"XDG_CACHE_HOME": "/tmp/goto/which-cache"}
+    shutil.rmtree("/tmp/goto/which-cache", ignore_errors=True)
+    started = time.monotonic()
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY,
+                                "which", "/tmp/goto/dir1"], env=env)
+    assert time.monotonic() - started < 1.5, "Expected goto which not to wait for the provider"
+    assert ret == 1, f"Expected no match without cached results but got: {out}"
+
+    # Once cached by the menu, provided destinations are found
+    helper.create_config(helper.FILE_CONFIG, """
+[services]
+provider = 'printf "svc\\t/tmp/goto/dir1\\n"'
+""")
+    helper.run(["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY, "--list"], env=env)
+    helper.create_config(helper.FILE_CONFIG, """
+[services]
+provider = 'printf "svc\\t/tmp/goto/dir1\\n"'
+[slow]
+provider = "sleep 3"
+""")
+    started = time.monotonic()
+    ret, out, err = helper.run(["--config-file", helper.FILE_CONFIG, "--history-file", helper.FILE_HISTORY,
+                                "which", "/tmp/goto/dir1"], env=env)
+    assert time.monotonic() - started < 1.5, "Expected goto which not to wait for the provider"
+    assert out.strip() == "svc", f"Expected the cached destination but got: {out}"